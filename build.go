@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"go/build"
+	"go/build/constraint"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jstemmer/gotags/tags"
+)
+
+// newBuildContext returns the build context for goos and goarch with the
+// additional comma separated build tags. An empty goos or goarch selects the
+// default of the go tool. Cgo is only enabled when building for the host.
+func newBuildContext(goos, goarch, tags string) build.Context {
+	ctx := build.Default
+	if goos != "" {
+		ctx.GOOS = goos
+	}
+	if goarch != "" {
+		ctx.GOARCH = goarch
+	}
+	ctx.CgoEnabled = build.Default.CgoEnabled && ctx.GOOS == build.Default.GOOS && ctx.GOARCH == build.Default.GOARCH
+
+	ctx.BuildTags = nil
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			ctx.BuildTags = append(ctx.BuildTags, tag)
+		}
+	}
+	return ctx
+}
+
+// filterFiles returns the files in files whose build constraints are satisfied
+// in ctx, using the same rules as the go tool. Files whose constraints cannot
+// be read are kept, so that their errors are reported when they are parsed.
+func filterFiles(ctx build.Context, files []string) []string {
+	var filtered []string
+	for _, file := range files {
+		ok, err := ctx.MatchFile(filepath.Dir(file), filepath.Base(file))
+		if err != nil || ok {
+			filtered = append(filtered, file)
+		}
+	}
+	return filtered
+}
+
+// fileConstraint returns the build constraint of filename, combining the
+// constraints implied by its name with its //go:build line (or its // +build
+// lines if it has no //go:build line). If the file has no constraints, nil
+// is returned.
+func fileConstraint(filename string) (constraint.Expr, error) {
+	expr := nameConstraint(filename)
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	lineExpr, err := headerConstraint(f)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case expr == nil:
+		return lineExpr, nil
+	case lineExpr == nil:
+		return expr, nil
+	}
+	return &constraint.AndExpr{X: expr, Y: lineExpr}, nil
+}
+
+// nameConstraint returns the build constraint implied by the GOOS and GOARCH
+// suffixes of filename, e.g. linux && amd64 for file_linux_amd64.go.
+func nameConstraint(filename string) constraint.Expr {
+	name := strings.TrimSuffix(filepath.Base(filename), ".go")
+	name = strings.TrimSuffix(name, "_test")
+
+	parts := strings.Split(name, "_")
+	if len(parts) < 2 {
+		return nil
+	}
+	// the file name without suffixes is not a constraint, e.g. linux.go
+	parts = parts[1:]
+
+	n := len(parts)
+	if n >= 2 && isKnownOS(parts[n-2]) && isKnownArch(parts[n-1]) {
+		return &constraint.AndExpr{
+			X: &constraint.TagExpr{Tag: parts[n-2]},
+			Y: &constraint.TagExpr{Tag: parts[n-1]},
+		}
+	}
+	if isKnownOS(parts[n-1]) || isKnownArch(parts[n-1]) {
+		return &constraint.TagExpr{Tag: parts[n-1]}
+	}
+	return nil
+}
+
+// knownOS and knownArch are the operating systems and architectures the go
+// tool recognizes in file name suffixes, as listed in go/build's syslist.
+var (
+	knownOS = map[string]bool{
+		"aix": true, "android": true, "darwin": true, "dragonfly": true,
+		"freebsd": true, "hurd": true, "illumos": true, "ios": true, "js": true,
+		"linux": true, "nacl": true, "netbsd": true, "openbsd": true,
+		"plan9": true, "solaris": true, "wasip1": true, "windows": true,
+		"zos": true,
+	}
+	knownArch = map[string]bool{
+		"386": true, "amd64": true, "amd64p32": true, "arm": true, "armbe": true,
+		"arm64": true, "arm64be": true, "loong64": true, "mips": true,
+		"mipsle": true, "mips64": true, "mips64le": true, "mips64p32": true,
+		"mips64p32le": true, "ppc": true, "ppc64": true, "ppc64le": true,
+		"riscv": true, "riscv64": true, "s390": true, "s390x": true,
+		"sparc": true, "sparc64": true, "wasm": true,
+	}
+)
+
+// isKnownOS reports whether the go tool treats name as an operating system in
+// file name suffixes such as _linux.go.
+func isKnownOS(name string) bool {
+	return knownOS[name]
+}
+
+// isKnownArch reports whether the go tool treats name as an architecture in
+// file name suffixes such as _amd64.go.
+func isKnownArch(name string) bool {
+	return knownArch[name]
+}
+
+// headerConstraint returns the build constraint in the file header read from
+// r, i.e. the comments before the package clause.
+func headerConstraint(r io.Reader) (constraint.Expr, error) {
+	var goBuild constraint.Expr
+	var plusBuild []constraint.Expr
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if !bytes.HasPrefix(line, []byte("//")) {
+			break
+		}
+
+		text := string(line)
+		if constraint.IsGoBuild(text) {
+			expr, err := constraint.Parse(text)
+			if err != nil {
+				return nil, err
+			}
+			goBuild = expr
+		} else if constraint.IsPlusBuild(text) {
+			expr, err := constraint.Parse(text)
+			if err != nil {
+				return nil, err
+			}
+			plusBuild = append(plusBuild, expr)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if goBuild != nil {
+		return goBuild, nil
+	}
+
+	var expr constraint.Expr
+	for _, e := range plusBuild {
+		if expr == nil {
+			expr = e
+		} else {
+			expr = &constraint.AndExpr{X: expr, Y: e}
+		}
+	}
+	return expr, nil
+}
+
+// annotateBuildConstraints sets the build field of each tag in parsed to the
+// build constraint of the file in files it belongs to.
+func annotateBuildConstraints(parsed []tags.Tag, files []string, basedir string) {
+	opts := parseOptions(basedir)
+	constraints := make(map[string]string)
+	for _, file := range files {
+		if expr, err := fileConstraint(file); err == nil && expr != nil {
+			constraints[opts.FileName(file)] = expr.String()
+		}
+	}
+
+	for _, tag := range parsed {
+		if c, ok := constraints[tag.File]; ok {
+			tag.Fields[tags.Build] = c
+		}
+	}
+}
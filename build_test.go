@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFileConstraint(t *testing.T) {
+	tests := []struct {
+		filename   string
+		constraint string
+	}{
+		{"testdata/build/plain.go", "<nil>"},
+		{"testdata/build/file_windows.go", "windows"},
+		{"testdata/build/file_linux_arm64.go", "linux && arm64"},
+		{"testdata/build/gen.go", "ignore"},
+		{"testdata/build/integration.go", "integration && !race"},
+		{"testdata/build/plusbuild_unix.go", "(linux || darwin) && cgo"},
+	}
+
+	for _, test := range tests {
+		expr, err := fileConstraint(test.filename)
+		if err != nil {
+			t.Errorf("[%s] unexpected error from fileConstraint: %s", test.filename, err)
+			continue
+		}
+		if s := fmt.Sprint(expr); s != test.constraint {
+			t.Errorf("[%s] fileConstraint() = %s, want %s", test.filename, s, test.constraint)
+		}
+	}
+}
+
+func TestNameConstraint(t *testing.T) {
+	tests := []struct {
+		filename   string
+		constraint string
+	}{
+		{"linux.go", "<nil>"},
+		{"file_amd64.go", "amd64"},
+		{"file_darwin_test.go", "darwin"},
+		{"file_js_wasm.go", "js && wasm"},
+		{"file_unknown.go", "<nil>"},
+		{"file_amd64_linux.go", "linux"},
+		{"file_unix.go", "<nil>"},
+	}
+
+	for _, test := range tests {
+		if s := fmt.Sprint(nameConstraint(test.filename)); s != test.constraint {
+			t.Errorf("nameConstraint(%q) = %s, want %s", test.filename, s, test.constraint)
+		}
+	}
+}
+
+func TestFilterFiles(t *testing.T) {
+	files := []string{
+		"testdata/build/file_linux_arm64.go",
+		"testdata/build/file_windows.go",
+		"testdata/build/gen.go",
+		"testdata/build/integration.go",
+		"testdata/build/plain.go",
+		"testdata/build/missing.go",
+	}
+
+	tests := []struct {
+		goos, goarch, tags string
+		files              []string
+	}{
+		{"windows", "amd64", "", []string{"file_windows.go", "plain.go", "missing.go"}},
+		{"linux", "arm64", "integration", []string{"file_linux_arm64.go", "integration.go", "plain.go", "missing.go"}},
+		{"android", "arm64", "", []string{"file_linux_arm64.go", "plain.go", "missing.go"}},
+		{"linux", "amd64", "ignore,race,integration", []string{"gen.go", "plain.go", "missing.go"}},
+	}
+
+	for _, test := range tests {
+		ctx := newBuildContext(test.goos, test.goarch, test.tags)
+
+		var names []string
+		for _, file := range filterFiles(ctx, files) {
+			names = append(names, strings.TrimPrefix(file, "testdata/build/"))
+		}
+		if fmt.Sprint(names) != fmt.Sprint(test.files) {
+			t.Errorf("[%s/%s %s] filterFiles() = %v, want %v", test.goos, test.goarch, test.tags, names, test.files)
+		}
+	}
+}
+
+func TestFilterFilesHost(t *testing.T) {
+	files := filterFiles(newBuildContext("", "", ""), []string{"testdata/build/gen.go", "testdata/build/plain.go"})
+	if fmt.Sprint(files) != "[testdata/build/plain.go]" {
+		t.Errorf("filterFiles() = %v, want [testdata/build/plain.go]", files)
+	}
+}
+
+func TestGetFileNamesBuild(t *testing.T) {
+	defer func(o, a string, r bool) { goos, goarch, recurse = o, a, r }(goos, goarch, recurse)
+	goos, goarch = "linux", "amd64"
+
+	// files named explicitly are included whatever their constraints
+	names := []string{"testdata/build/file_windows.go", "testdata/build/_under.go"}
+	files, err := getFileNames(names)
+	if err != nil {
+		t.Fatalf("unexpected error from getFileNames: %s", err)
+	}
+	if fmt.Sprint(files) != fmt.Sprint(names) {
+		t.Errorf("getFileNames(%v) = %v, want %v", names, files, names)
+	}
+
+	// files found in directories are selected by their constraints
+	goos = "windows"
+	recurse = true
+	files, err = getFileNames([]string{"testdata/build"})
+	if err != nil {
+		t.Fatalf("unexpected error from getFileNames: %s", err)
+	}
+	expected := []string{"testdata/build/file_windows.go", "testdata/build/plain.go"}
+	if fmt.Sprint(files) != fmt.Sprint(expected) {
+		t.Errorf("getFileNames(testdata/build) = %v, want %v", files, expected)
+	}
+}
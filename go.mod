@@ -0,0 +1,3 @@
+module github.com/jstemmer/gotags
+
+go 1.20
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/jstemmer/gotags/tags"
+)
+
+// lookupFile returns the name of the tags file used by -lookup.
+func lookupFile() string {
+	if outputFile == "" || outputFile == "-" {
+		return "tags"
+	}
+	return outputFile
+}
+
+// lookup writes the tags named name in the tags file filename to w, or the
+// tags whose name starts with name if prefix is set. It returns the number of
+// tags that were found.
+func lookup(w io.Writer, filename, name string, prefix bool) (int, error) {
+	f, err := tags.OpenTagFile(filename)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var found []tags.Tag
+	if prefix {
+		found, err = f.LookupPrefix(name)
+	} else {
+		found, err = f.Lookup(name)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	for _, t := range found {
+		if _, err := fmt.Fprintln(w, t.String()); err != nil {
+			return 0, err
+		}
+	}
+	return len(found), nil
+}
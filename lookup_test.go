@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	lines := "!_TAG_FILE_FORMAT\t2\n" +
+		"!_TAG_FILE_SORTED\t1\t/0=unsorted, 1=sorted/\n" +
+		"Constant\ttags/testdata/const.go\t3;\"\tc\taccess:public\tline:3\ttype:string\n" +
+		"Function1\ttags/testdata/func.go\t3;\"\tf\taccess:public\tline:3\tsignature:()\ttype:string\n" +
+		"Function2\ttags/testdata/func.go\t5;\"\tf\taccess:public\tline:5\tsignature:(p1 int, p2 string)\n" +
+		"Struct\ttags/testdata/struct.go\t3;\"\tt\taccess:public\tline:3\ttype:struct\n"
+
+	filename := filepath.Join(t.TempDir(), "tags")
+	if err := os.WriteFile(filename, []byte(lines), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name   string
+		prefix bool
+		want   string
+	}{
+		{"Function1", false, "Function1\ttags/testdata/func.go\t3;\"\tf\taccess:public\tline:3\tsignature:()\ttype:string\n"},
+		{"Function", false, ""},
+		{"Function", true, "Function1\ttags/testdata/func.go\t3;\"\tf\taccess:public\tline:3\tsignature:()\ttype:string\n" +
+			"Function2\ttags/testdata/func.go\t5;\"\tf\taccess:public\tline:5\tsignature:(p1 int, p2 string)\n"},
+	}
+
+	for _, test := range tests {
+		var b bytes.Buffer
+		n, err := lookup(&b, filename, test.name, test.prefix)
+		if err != nil {
+			t.Errorf("[%s] unexpected error from lookup: %s", test.name, err)
+			continue
+		}
+		if b.String() != test.want {
+			t.Errorf("lookup %s (prefix=%t)\n  is:%q\nwant:%q", test.name, test.prefix, b.String(), test.want)
+		}
+		if want := bytes.Count([]byte(test.want), []byte("\n")); n != want {
+			t.Errorf("lookup %s returned %d, want %d", test.name, n, want)
+		}
+	}
+
+	if _, err := lookup(&bytes.Buffer{}, filepath.Join(t.TempDir(), "missing"), "Struct", false); err == nil {
+		t.Errorf("expected lookup in missing file to return error")
+	}
+}
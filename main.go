@@ -8,8 +8,11 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
-	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/jstemmer/gotags/tags"
 )
 
 // Contants used for the meta tags
@@ -22,16 +25,39 @@ const (
 )
 
 var (
-	printVersion bool
-	inputFile    string
-	outputFile   string
-	recurse      bool
-	sortOutput   bool
-	silent       bool
-	relative     bool
-	listLangs    bool
-	fields       string
-	extraSymbols string
+	printVersion   bool
+	inputFile      string
+	outputFile     string
+	recurse        bool
+	sortOutput     bool
+	silent         bool
+	relative       bool
+	listLangs      bool
+	listKindsOpt   bool
+	kinds          string
+	fields         string
+	extraSymbols   string
+	extraSeparator string
+	excmd          string
+	format         string
+	etags          bool
+	xref           bool
+	packageMode    bool
+	typeCheck      bool
+	jobs           int
+	update         bool
+	watchMode      bool
+	watchPeriod    time.Duration
+	goos           string
+	goarch         string
+	buildTags      string
+	allBuilds      bool
+	lookupName     string
+	lookupPrefix   bool
+
+	fieldSet  = tags.DefaultFields() // extension fields to include
+	symbolSet = tags.FieldSet{}      // additional tags to include
+	kindSet   = tags.DefaultKinds()  // kinds of tags to include
 )
 
 // ignore unknown flags
@@ -47,28 +73,56 @@ func init() {
 	flags.BoolVar(&silent, "silent", false, "do not produce any output on error.")
 	flags.BoolVar(&relative, "tag-relative", false, "file paths should be relative to the directory containing the tag file.")
 	flags.BoolVar(&listLangs, "list-languages", false, "list supported languages.")
-	flags.StringVar(&fields, "fields", "", "include selected extension fields (only +l).")
-	flags.StringVar(&extraSymbols, "extra", "", "include additional tags with package and receiver name prefixes (+q)")
+	flags.BoolVar(&listKindsOpt, "list-kinds", false, "list the kinds of tags and whether they are enabled by default.")
+	flags.StringVar(&kinds, "kinds-Go", "", "enable or disable kinds of tags, e.g. +f+m-i-w or {function}{method}.")
+	flags.StringVar(&kinds, "go-kinds", "", "same as -kinds-Go.")
+	flags.StringVar(&fields, "fields", "", "include selected extension fields, e.g. +l-S or +{language}.")
+	flags.StringVar(&extraSymbols, "extra", "", "include additional tags with names qualified by package and scope (+q) or by import path and scope (+Q), tags for symbols declared inside functions (+l), reference tags (+r), or tags for struct tag keys (+k).")
+	flags.StringVar(&extraSeparator, "extra-separator", ".", "separator used in the qualified names of extra tags.")
+	flags.StringVar(&excmd, "excmd", "number", "address of the tags: number, pattern, combine or mixed (as in ctags, the same as pattern for Go).")
+	flags.StringVar(&format, "format", "ctags", "output format (ctags, etags, json, xref).")
+	flags.BoolVar(&etags, "e", false, "output an Emacs TAGS file, same as -format=etags.")
+	flags.BoolVar(&xref, "x", false, "output a cross reference listing, same as -format=xref.")
+	flags.BoolVar(&packageMode, "pkg", false, "parse files of the same package together to find constructors declared in other files.")
+	flags.BoolVar(&typeCheck, "types", false, "type check each package to add the interfaces implemented by types and tags for promoted methods, implies -pkg.")
+	flags.IntVar(&jobs, "j", runtime.GOMAXPROCS(0), "number of files to parse concurrently.")
+	flags.BoolVar(&update, "a", false, "update the tags of the specified files in an existing tags file.")
+	flags.BoolVar(&update, "update", false, "same as -a.")
+	flags.BoolVar(&watchMode, "watch", false, "keep the tags file up to date by watching the specified files for changes.")
+	flags.DurationVar(&watchPeriod, "watch-interval", 500*time.Millisecond, "interval at which watched files are checked for changes.")
+	flags.StringVar(&goos, "goos", "", "select files by their build constraints for this operating system instead of the host operating system.")
+	flags.StringVar(&goarch, "goarch", "", "select files by their build constraints for this architecture instead of the host architecture.")
+	flags.StringVar(&buildTags, "tags", "", "comma separated list of additional build tags satisfied by the build constraints.")
+	flags.BoolVar(&allBuilds, "all-builds", false, "include all files regardless of build constraints and add their constraints in a build field.")
+	flags.StringVar(&lookupName, "lookup", "", `print the tags named NAME in the tags file set with -f (default "tags") and exit.`)
+	flags.BoolVar(&lookupPrefix, "prefix", false, "with -lookup, print the tags whose name starts with NAME.")
 
 	flags.Usage = func() {
 		fmt.Fprintf(os.Stderr, "gotags version %s\n\n", Version)
-		fmt.Fprintf(os.Stderr, "Usage: %s [options] file(s)\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] file(s) | package(s)\n\n", os.Args[0])
 		flags.PrintDefaults()
 	}
 }
 
+// walkDir appends the Go files in dir and its subdirectories to names. Unless
+// allBuilds is set, files excluded by their build constraints for the target
+// set with -goos, -goarch and -tags (by default the host) are skipped.
 func walkDir(names []string, dir string) ([]string, error) {
+	var found []string
 	e := filepath.Walk(dir, func(path string, finfo os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 		if strings.HasSuffix(path, ".go") && !finfo.IsDir() {
-			names = append(names, path)
+			found = append(found, path)
 		}
 		return nil
 	})
 
-	return names, e
+	if !allBuilds {
+		found = filterFiles(newBuildContext(goos, goarch, buildTags), found)
+	}
+	return append(names, found...), e
 }
 
 func recurseNames(names []string) ([]string, error) {
@@ -115,23 +169,52 @@ func readNames(names []string) ([]string, error) {
 	return names, nil
 }
 
-func getFileNames() ([]string, error) {
+// getInputNames returns the names of the files and directories specified on the
+// command line and in the input file.
+func getInputNames() ([]string, error) {
 	var names []string
 
 	names = append(names, flags.Args()...)
-	names, err := readNames(names)
-	if err != nil {
-		return nil, err
+	return readNames(names)
+}
+
+// getFileNames returns the file names in names. When recurse is set, the
+// directories in names are replaced by the files they contain. Go package
+// patterns in names are replaced by the files of the matching packages;
+// packages that cannot be loaded are reported on stderr unless silent is set
+// and skipped. Build constraints only apply to the files found in directories
+// and packages, files named explicitly are always included.
+func getFileNames(names []string) ([]string, error) {
+	var patterns, files []string
+	for _, name := range names {
+		if isPackagePattern(name) {
+			patterns = append(patterns, packagePattern(name))
+		} else {
+			files = append(files, name)
+		}
 	}
 
 	if recurse {
-		names, err = recurseNames(names)
+		var err error
+		files, err = recurseNames(files)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	return names, nil
+	if len(patterns) > 0 {
+		pkgFiles, errs, err := listPackageFiles(patterns)
+		if err != nil {
+			return nil, err
+		}
+		if !silent {
+			for _, err := range errs {
+				fmt.Fprintf(os.Stderr, "package error: %s\n\n", err)
+			}
+		}
+		files = append(files, pkgFiles...)
+	}
+	return files, nil
 }
 
 func main() {
@@ -149,13 +232,39 @@ func main() {
 		return
 	}
 
-	files, err := getFileNames()
+	if listKindsOpt {
+		tags.ListKinds(os.Stdout)
+		return
+	}
+
+	if lookupName != "" {
+		n, err := lookup(os.Stdout, lookupFile(), lookupName, lookupPrefix)
+		if err != nil {
+			if !silent {
+				fmt.Fprintf(os.Stderr, "could not look up tags: %s\n", err)
+			}
+			os.Exit(1)
+		}
+		if n == 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	names, err := getInputNames()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "cannot get specified files\n\n")
 		flags.Usage()
 		os.Exit(1)
 	}
 
+	files, err := getFileNames(names)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot get specified files: %s\n\n", err)
+		flags.Usage()
+		os.Exit(1)
+	}
+
 	if len(files) == 0 && len(inputFile) == 0 {
 		fmt.Fprintf(os.Stderr, "no file specified\n\n")
 		flags.Usage()
@@ -173,76 +282,157 @@ func main() {
 		}
 	}
 
-	fieldSet, err := parseFields(fields)
+	fieldSet, err = tags.ParseFields(fields)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n\n", err)
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	symbolSet, err = tags.ParseExtras(extraSymbols)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%s\n\n", err)
 		flags.Usage()
 		os.Exit(1)
 	}
 
-	symbolSet, err := parseExtraSymbols(extraSymbols)
+	kindSet, err = tags.ParseKinds(kinds)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%s\n\n", err)
 		flags.Usage()
 		os.Exit(1)
 	}
 
-	tags := []Tag{}
-	for _, file := range files {
-		ts, err := Parse(file, relative, basedir, symbolSet)
+	if !tags.Excmd(excmd).Valid() {
+		fmt.Fprintf(os.Stderr, "invalid excmd: %s\n\n", excmd)
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	if etags {
+		format = "etags"
+	}
+	if xref {
+		format = "xref"
+	}
+
+	write, ok := writers[format]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "invalid format: %s\n\n", format)
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	if (update || watchMode) && (format != "ctags" || len(outputFile) == 0 || outputFile == "-") {
+		fmt.Fprintf(os.Stderr, "updating requires the ctags format and an output file\n\n")
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	if update {
+		write, err = updateWriter(files, basedir)
 		if err != nil {
-			if !silent {
-				fmt.Fprintf(os.Stderr, "parse error: %s\n\n", err)
-			}
-			continue
+			fmt.Fprintf(os.Stderr, "could not read tags file: %s\n", err)
+			os.Exit(1)
 		}
-		tags = append(tags, ts...)
 	}
 
-	output := createMetaTags()
-	for _, tag := range tags {
-		if fieldSet.Includes(Language) {
-			tag.Fields[Language] = "Go"
+	parsed := parseFiles(files, basedir)
+	if err := writeTags(write, parsed); err != nil {
+		fmt.Fprintf(os.Stderr, "could not write output: %s\n", err)
+		os.Exit(1)
+	}
+
+	if watchMode {
+		watch(names, files, basedir)
+	}
+}
+
+// parseFiles parses files and returns their tags, containing the fields
+// selected in fieldSet. Parse errors are reported on stderr unless silent is
+// set.
+func parseFiles(files []string, basedir string) []tags.Tag {
+	var groups [][]string
+	if packageMode || typeCheck {
+		groups = tags.GroupPackages(files)
+	} else {
+		for _, file := range files {
+			groups = append(groups, []string{file})
 		}
-		output = append(output, tag.String())
 	}
 
-	if sortOutput {
-		sort.Sort(sort.StringSlice(output))
+	parsed, errs := tags.ParseGroups(groups, jobs, parseOptions(basedir))
+	if !silent {
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "parse error: %s\n\n", err)
+		}
+	}
+
+	if allBuilds {
+		annotateBuildConstraints(parsed, files, basedir)
 	}
 
-	var out io.Writer
+	parsed = kindSet.Filter(parsed)
+	for i, tag := range parsed {
+		fieldSet.Apply(tag)
+		parsed[i].Address = tags.Excmd(excmd).Address(tag)
+	}
+	return parsed
+}
+
+// writeTags writes the meta tags and parsed to outputFile using write. The
+// file is replaced atomically, so readers never see a partially written file.
+// If outputFile is not set, the tags are written to standard out.
+func writeTags(write func(io.Writer, []tags.MetaTag, []tags.Tag, tags.WriteOptions) error, parsed []tags.Tag) error {
+	writeAll := func(w io.Writer) error {
+		return write(w, createMetaTags(), parsed, writeOptions())
+	}
 	if len(outputFile) == 0 || outputFile == "-" {
 		// For compatibility with older gotags versions, also write to stdout
 		// when outputFile is not specified.
-		out = os.Stdout
-	} else {
-		file, err := os.Create(outputFile)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "could not create output file: %s\n", err)
-			os.Exit(1)
-		}
-		out = file
-		defer file.Close()
+		return writeAll(os.Stdout)
 	}
+	return writeFileAtomic(outputFile, writeAll)
+}
 
-	for _, s := range output {
-		fmt.Fprintln(out, s)
+// parseOptions returns the options used to parse files, with file names
+// relative to basedir if relative is set.
+func parseOptions(basedir string) tags.Options {
+	return tags.Options{
+		Relative:  relative,
+		BasePath:  basedir,
+		Extra:     symbolSet,
+		Separator: extraSeparator,
+		TypeCheck: typeCheck,
 	}
 }
 
+// writers contains a function to write the tags for each output format.
+var writers = map[string]func(io.Writer, []tags.MetaTag, []tags.Tag, tags.WriteOptions) error{
+	"ctags": tags.WriteCtags,
+	"etags": tags.WriteEtags,
+	"json":  tags.WriteJSON,
+	"xref":  tags.WriteXref,
+}
+
+// writeOptions returns the options used to write the tags.
+func writeOptions() tags.WriteOptions {
+	return tags.WriteOptions{Sort: sortOutput, Fields: fieldSet}
+}
+
 // createMetaTags returns a list of meta tags.
-func createMetaTags() []string {
+func createMetaTags() []tags.MetaTag {
 	var sorted int
 	if sortOutput {
 		sorted = 1
 	}
-	return []string{
-		"!_TAG_FILE_FORMAT\t2",
-		fmt.Sprintf("!_TAG_FILE_SORTED\t%d\t/0=unsorted, 1=sorted/", sorted),
-		fmt.Sprintf("!_TAG_PROGRAM_AUTHOR\t%s\t/%s/", AuthorName, AuthorEmail),
-		fmt.Sprintf("!_TAG_PROGRAM_NAME\t%s", Name),
-		fmt.Sprintf("!_TAG_PROGRAM_URL\t%s", URL),
-		fmt.Sprintf("!_TAG_PROGRAM_VERSION\t%s\t/%s/", Version, runtime.Version()),
-	}
+	metaTags := fieldSet.Descriptions()
+	return append(metaTags, []tags.MetaTag{
+		{Name: "TAG_FILE_FORMAT", Value: "2"},
+		{Name: "TAG_FILE_SORTED", Value: strconv.Itoa(sorted), Comment: "0=unsorted, 1=sorted"},
+		{Name: "TAG_PROGRAM_AUTHOR", Value: AuthorName, Comment: AuthorEmail},
+		{Name: "TAG_PROGRAM_NAME", Value: Name},
+		{Name: "TAG_PROGRAM_URL", Value: URL},
+		{Name: "TAG_PROGRAM_VERSION", Value: Version, Comment: runtime.Version()},
+	}...)
 }
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// isPackagePattern reports whether name is a Go package pattern, such as
+// ./..., std or github.com/jstemmer/gotags, instead of the name of a file or
+// of a directory to recurse into. The rules of the go command are followed:
+// names containing ... and the reserved names std, all and cmd are patterns,
+// as are existing directories such as ./cmd and import paths. Names ending in
+// .go and other existing files are always file names, so a mistyped file name
+// is reported as a missing file. When recurse is set, existing directories are
+// recursed into instead.
+func isPackagePattern(name string) bool {
+	switch {
+	case name == "":
+		return false
+	case strings.Contains(name, "..."), name == "std", name == "all", name == "cmd":
+		return true
+	case strings.HasSuffix(name, ".go"):
+		return false
+	}
+	if info, err := os.Stat(name); err == nil {
+		return info.IsDir() && !recurse
+	}
+	return true
+}
+
+// packagePattern returns the package pattern name in the form expected by go
+// list. Existing directories that are not written as a local path, such as
+// cmd, are prefixed with ./ so they are not mistaken for an import path.
+func packagePattern(name string) string {
+	if filepath.IsAbs(name) || name == "." || name == ".." ||
+		strings.HasPrefix(name, "./") || strings.HasPrefix(name, "../") {
+		return name
+	}
+	if info, err := os.Stat(name); err == nil && info.IsDir() {
+		return "./" + name
+	}
+	return name
+}
+
+// listedPackage contains the fields of a package printed by go list -json.
+type listedPackage struct {
+	Dir            string
+	ImportPath     string
+	GoFiles        []string
+	CgoFiles       []string
+	IgnoredGoFiles []string
+	Error          *struct {
+		Err string
+	}
+}
+
+// listPackageFiles returns the Go source files of the packages matching
+// patterns. The patterns are resolved by the go list command, so the same
+// rules apply as when building the packages: go.mod and the vendor directory
+// are respected, files excluded by build constraints are skipped and testdata
+// directories and directories starting with _ or . are ignored. Files inside
+// the current directory are returned as relative paths.
+//
+// Packages that cannot be loaded, for example because they do not exist, do
+// not stop the listing: their errors are returned in errs, one for each
+// package, together with the files of the other packages. Only a failure of
+// the go command itself is returned as err.
+//
+// The -goos, -goarch and -tags options are passed on to go list. If
+// allBuilds is set, the files excluded by build constraints are returned too.
+func listPackageFiles(patterns []string) (files []string, errs []error, err error) {
+	args := []string{"list", "-e", "-json=Dir,ImportPath,GoFiles,CgoFiles,IgnoredGoFiles,Error"}
+	if buildTags != "" {
+		args = append(args, "-tags", buildTags)
+	}
+	args = append(args, "--")
+	cmd := exec.Command("go", append(args, patterns...)...)
+
+	cmd.Env = os.Environ()
+	if goos != "" {
+		cmd.Env = append(cmd.Env, "GOOS="+goos)
+	}
+	if goarch != "" {
+		cmd.Env = append(cmd.Env, "GOARCH="+goarch)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, nil, fmt.Errorf("go list: %s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var pkg listedPackage
+		if err := dec.Decode(&pkg); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, fmt.Errorf("go list: %s", err)
+		}
+
+		if pkg.Error != nil {
+			errs = append(errs, errors.New(pkg.Error.Err))
+		}
+
+		names := append(pkg.GoFiles, pkg.CgoFiles...)
+		if allBuilds {
+			names = append(names, pkg.IgnoredGoFiles...)
+		}
+		if len(names) == 0 {
+			continue
+		}
+
+		dir := pkg.Dir
+		if rel, err := filepath.Rel(wd, dir); err == nil && !strings.HasPrefix(rel, "..") {
+			dir = rel
+		}
+		for _, name := range names {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	return files, errs, nil
+}
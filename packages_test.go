@@ -0,0 +1,119 @@
+package main
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsPackagePattern(t *testing.T) {
+	defer func(r bool) { recurse = r }(recurse)
+
+	tests := []struct {
+		name    string
+		recurse bool
+		pattern bool
+	}{
+		{"./...", false, true},
+		{"./...", true, true},
+		{"github.com/jstemmer/gotags/...", false, true},
+		{"std", false, true},
+		{"all", false, true},
+		{"cmd", false, true},
+		{"net/http", false, true},
+		{"example.com/missing", false, true},
+		{"testdata", false, true},
+		{"./tags", false, true},
+		{"testdata", true, false},
+		{"./tags", true, false},
+		{"tags/testdata/const.go", false, false},
+		{"tags/testdata/missing.go", false, false},
+		{"tags/testdata/missing.go", true, false},
+		{"README.md", false, false},
+		{"", false, false},
+	}
+
+	for _, test := range tests {
+		recurse = test.recurse
+		if p := isPackagePattern(test.name); p != test.pattern {
+			t.Errorf("isPackagePattern(%q) with recurse %t = %t, want %t", test.name, test.recurse, p, test.pattern)
+		}
+	}
+}
+
+func TestPackagePattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+	}{
+		{"tags", "./tags"},
+		{"./tags", "./tags"},
+		{".", "."},
+		{"../gotags", "../gotags"},
+		{"/usr/lib/go/src/errors", "/usr/lib/go/src/errors"},
+		{"./...", "./..."},
+		{"std", "std"},
+		{"net/http", "net/http"},
+	}
+
+	for _, test := range tests {
+		if p := packagePattern(test.name); p != test.pattern {
+			t.Errorf("packagePattern(%q) = %q, want %q", test.name, p, test.pattern)
+		}
+	}
+}
+
+func TestListPackageFiles(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not found")
+	}
+
+	files, errs, err := listPackageFiles([]string{"errors"})
+	if err != nil {
+		t.Fatalf("unexpected error from listPackageFiles: %s", err)
+	}
+	if len(errs) > 0 {
+		t.Fatalf("unexpected package errors from listPackageFiles: %v", errs)
+	}
+
+	names := make(map[string]bool)
+	for _, file := range files {
+		names[filepath.Base(file)] = true
+	}
+	if !names["errors.go"] {
+		t.Errorf("expected files %v to include errors.go", files)
+	}
+	for name := range names {
+		if filepath.Ext(name) != ".go" || filepath.Base(name) == "errors_test.go" {
+			t.Errorf("unexpected file %s", name)
+		}
+	}
+}
+
+func TestListPackageFilesErrors(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not found")
+	}
+
+	files, errs, err := listPackageFiles([]string{"errors", "example.invalid/missing"})
+	if err != nil {
+		t.Fatalf("unexpected error from listPackageFiles: %s", err)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("got %d package errors %v, want 1", len(errs), errs)
+	}
+	if !strings.Contains(errs[0].Error(), "example.invalid/missing") {
+		t.Errorf("package error %q does not mention the missing package", errs[0])
+	}
+
+	var found bool
+	for _, file := range files {
+		if filepath.Base(file) == "errors.go" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected files %v of the other package to include errors.go", files)
+	}
+}
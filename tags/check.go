@@ -0,0 +1,222 @@
+package tags
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// typeChecker imports the packages used by the type checked packages. The
+// imported packages are type checked from source once and shared by all
+// packages checked with the same typeChecker, which is safe for concurrent
+// use. The files of the checked packages must be parsed using fset.
+type typeChecker struct {
+	fset *token.FileSet
+
+	mu       sync.Mutex // guards importer
+	importer types.ImporterFrom
+}
+
+// newTypeChecker returns a typeChecker with an empty cache of imported
+// packages.
+func newTypeChecker() *typeChecker {
+	fset := token.NewFileSet()
+	return &typeChecker{
+		fset:     fset,
+		importer: importer.ForCompiler(fset, "source", nil).(types.ImporterFrom),
+	}
+}
+
+// Import implements types.Importer.
+func (c *typeChecker) Import(path string) (*types.Package, error) {
+	return c.ImportFrom(path, "", 0)
+}
+
+// ImportFrom implements types.ImporterFrom.
+func (c *typeChecker) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.importer.ImportFrom(path, dir, mode)
+}
+
+// checkTypes type checks files, which should all belong to the same package,
+// and uses the result to add the implements and implementedBy fields to the
+// type and interface tags in p.tags, and to create a tag for each method
+// promoted from an embedded field of a struct. Imported packages are type
+// checked from source by p.opts.checker, so no compiled packages are needed
+// and they are only checked once per run. Type errors are
+// ignored, the information that could be determined is used anyway.
+func (p *tagParser) checkTypes(files []*ast.File) {
+	if !p.opts.TypeCheck || len(files) == 0 {
+		return
+	}
+
+	path := packageImportPath(filepath.Dir(p.fset.File(files[0].Pos()).Name()))
+	if path == "" {
+		path = files[0].Name.Name
+	}
+
+	conf := types.Config{
+		Importer: p.opts.checker,
+		Error:    func(error) {},
+	}
+	pkg, _ := conf.Check(path, p.fset, files, nil)
+	if pkg == nil {
+		return
+	}
+
+	named, ifaces := packageTypes(pkg)
+	implements := make(map[string][]string)
+	implementedBy := make(map[string][]string)
+	for _, t := range named {
+		for _, iface := range ifaces {
+			if iface.Obj() == t.Obj() || !implementsInterface(t, iface) {
+				continue
+			}
+			implements[t.Obj().Name()] = append(implements[t.Obj().Name()], typeName(pkg, iface))
+			if iface.Obj().Pkg() == pkg {
+				implementedBy[iface.Obj().Name()] = append(implementedBy[iface.Obj().Name()], t.Obj().Name())
+			}
+		}
+	}
+
+	for _, tag := range p.tags {
+		if tag.Fields[Scope] != "" {
+			continue
+		}
+		switch tag.Type {
+		case Type:
+			if names := implements[tag.Name]; len(names) > 0 {
+				tag.Fields[Implements] = strings.Join(names, ",")
+			}
+		case Interface:
+			if names := implementedBy[tag.Name]; len(names) > 0 {
+				tag.Fields[ImplementedBy] = strings.Join(names, ",")
+			}
+		}
+	}
+
+	for _, t := range named {
+		p.parsePromotedMethods(pkg, t)
+	}
+}
+
+// packageTypes returns the named non-interface types declared at the top level
+// of pkg, and the interfaces that these types may implement: the interfaces
+// declared in pkg and in the packages it imports, and the error interface.
+// Generic types and interfaces that are only usable as constraints are not
+// included. Both lists are sorted by name.
+func packageTypes(pkg *types.Package) (named, ifaces []*types.Named) {
+	add := func(scope *types.Scope, local bool) {
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || tn.IsAlias() || (!local && !tn.Exported()) {
+				continue
+			}
+			t, ok := tn.Type().(*types.Named)
+			if !ok || t.TypeParams().Len() > 0 {
+				continue
+			}
+			if iface, ok := t.Underlying().(*types.Interface); ok {
+				if iface.IsMethodSet() && iface.NumMethods() > 0 {
+					ifaces = append(ifaces, t)
+				}
+			} else if local {
+				named = append(named, t)
+			}
+		}
+	}
+
+	add(pkg.Scope(), true)
+	for _, imp := range pkg.Imports() {
+		add(imp.Scope(), false)
+	}
+	ifaces = append(ifaces, types.Universe.Lookup("error").Type().(*types.Named))
+
+	sort.SliceStable(ifaces, func(i, j int) bool { return typeName(pkg, ifaces[i]) < typeName(pkg, ifaces[j]) })
+	return named, ifaces
+}
+
+// implementsInterface reports whether t or a pointer to t implements iface.
+func implementsInterface(t, iface *types.Named) bool {
+	it := iface.Underlying().(*types.Interface)
+	return types.Implements(t, it) || types.Implements(types.NewPointer(t), it)
+}
+
+// typeName returns the name of t as it is written in package pkg, e.g. Shape
+// for a type declared in pkg and io.Writer for a type imported from io.
+func typeName(pkg *types.Package, t types.Type) string {
+	return types.TypeString(t, types.RelativeTo(pkg))
+}
+
+// parsePromotedMethods creates a tag for each method of a pointer to named
+// type t that is promoted from an embedded field. The tags point at the
+// declaration of the method, but have t as their scope, so that a reference
+// to x.Method finds the method when x has type t.
+func (p *tagParser) parsePromotedMethods(pkg *types.Package, t *types.Named) {
+	if _, ok := t.Underlying().(*types.Struct); !ok {
+		return
+	}
+
+	name := t.Obj().Name()
+	mset := types.NewMethodSet(types.NewPointer(t))
+	for i := 0; i < mset.Len(); i++ {
+		sel := mset.At(i)
+		fn, ok := sel.Obj().(*types.Func)
+		if !ok || len(sel.Index()) < 2 || !fn.Pos().IsValid() {
+			continue
+		}
+
+		p.loadSource(fn.Pos())
+		sig := fn.Type().(*types.Signature)
+		tag := p.createTag(fn.Name(), fn.Pos(), token.NoPos, Method)
+		tag.Fields[Access] = getAccess(tag.Name)
+		tag.Fields[Signature] = fmt.Sprintf("(%s)", tupleString(pkg, sig.Params(), sig.Variadic(), true))
+		tag.Fields[TypeField] = tupleString(pkg, sig.Results(), false, false)
+		tag.Fields[ReceiverType] = name
+		tag.Fields[Scope] = scopeOf(Type, name)
+		p.tags = append(p.tags, tag)
+	}
+}
+
+// loadSource reads the source of the file containing pos if it was not parsed
+// by p, so that tags in that file have a source line.
+func (p *tagParser) loadSource(pos token.Pos) {
+	filename := p.fset.File(pos).Name()
+	if _, ok := p.sources[filename]; ok {
+		return
+	}
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return
+	}
+	p.sources[filename] = src
+}
+
+// tupleString returns a comma separated list of the types in tuple, written
+// as in package pkg, in the same format as getTypes. If includeNames is true,
+// each type is preceded by its name, if it has one.
+func tupleString(pkg *types.Package, tuple *types.Tuple, variadic, includeNames bool) string {
+	elems := make([]string, tuple.Len())
+	for i := 0; i < tuple.Len(); i++ {
+		v := tuple.At(i)
+		t := typeName(pkg, v.Type())
+		if variadic && i == tuple.Len()-1 {
+			if s, ok := v.Type().(*types.Slice); ok {
+				t = "..." + typeName(pkg, s.Elem())
+			}
+		}
+		if includeNames && v.Name() != "" {
+			t = v.Name() + " " + t
+		}
+		elems[i] = t
+	}
+	return strings.Join(elems, ", ")
+}
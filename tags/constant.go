@@ -0,0 +1,254 @@
+package tags
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"unicode/utf8"
+)
+
+// constSpec describes the declaration of a constant.
+type constSpec struct {
+	typ  ast.Expr // declared or inherited type, nil if untyped
+	expr ast.Expr // declared or inherited value expression
+	iota int      // value of iota in the declaration
+
+	value     constant.Value // value, once evaluated
+	evaluated bool
+	busy      bool // the value is being evaluated
+}
+
+// collectConsts records the declaration of each constant declared at the top
+// level of files in p.consts, and the type expression of each type declared
+// there in p.typedef. Constant specs without type and values inherit those of
+// the preceding spec, as in the implicit repetition of a const block.
+func (p *tagParser) collectConsts(files []*ast.File) {
+	for _, f := range files {
+		for _, d := range f.Decls {
+			decl, ok := d.(*ast.GenDecl)
+			if ok && decl.Tok == token.TYPE {
+				for _, s := range decl.Specs {
+					ts := s.(*ast.TypeSpec)
+					if _, ok := p.typedef[ts.Name.Name]; !ok && ts.TypeParams == nil {
+						p.typedef[ts.Name.Name] = ts.Type
+					}
+				}
+			}
+			if !ok || decl.Tok != token.CONST {
+				continue
+			}
+
+			var typ ast.Expr
+			var values []ast.Expr
+			for i, s := range decl.Specs {
+				vs := s.(*ast.ValueSpec)
+				if vs.Type != nil || len(vs.Values) > 0 {
+					typ, values = vs.Type, vs.Values
+				}
+				for j, name := range vs.Names {
+					if _, ok := p.consts[name.Name]; ok || name.Name == "_" {
+						continue
+					}
+					c := &constSpec{typ: typ, iota: i}
+					if j < len(values) {
+						c.expr = values[j]
+					}
+					p.consts[name.Name] = c
+				}
+			}
+		}
+	}
+}
+
+// parseConstant sets the value field of tag, the tag of a constant declared
+// in v, and its type field if the type is inherited from a previous spec.
+func (p *tagParser) parseConstant(tag Tag, v *ast.ValueSpec) {
+	c, ok := p.consts[tag.Name]
+	if !ok {
+		return
+	}
+	if v.Type == nil && c.typ != nil {
+		tag.Fields[TypeField] = memberType(c.typ)
+	}
+	switch value := p.constValue(tag.Name); value.Kind() {
+	case constant.Unknown:
+	case constant.String:
+		// String shortens long strings
+		tag.Fields[Value] = value.ExactString()
+	default:
+		tag.Fields[Value] = value.String()
+	}
+}
+
+// constValue returns the value of the constant name, or an unknown value if it
+// cannot be determined, e.g. because it depends on a constant of another
+// package.
+func (p *tagParser) constValue(name string) constant.Value {
+	c, ok := p.consts[name]
+	if !ok || c.busy {
+		return constant.MakeUnknown()
+	}
+	if !c.evaluated {
+		c.busy = true
+		c.value, _ = p.evalConst(c.expr, c.iota)
+		c.busy = false
+		c.evaluated = true
+	}
+	return c.value
+}
+
+// evalConst evaluates constant expression expr, with iota set to the given
+// value. It returns the value and, if the type of expr is a predeclared
+// unsigned integer type, its size in bits, which is needed to compute bitwise
+// complements. An unknown value is returned if expr cannot be evaluated.
+func (p *tagParser) evalConst(expr ast.Expr, iota int) (value constant.Value, bits uint) {
+	// go/constant panics on operations that are invalid for their operands
+	defer func() {
+		if recover() != nil {
+			value, bits = constant.MakeUnknown(), 0
+		}
+	}()
+
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return constant.MakeFromLiteral(e.Value, e.Kind, 0), 0
+	case *ast.Ident:
+		switch e.Name {
+		case "iota":
+			return constant.MakeInt64(int64(iota)), 0
+		case "true", "false":
+			return constant.MakeBool(e.Name == "true"), 0
+		}
+		if c, ok := p.consts[e.Name]; ok {
+			bits = unsignedBits(c.typ)
+		}
+		return p.constValue(e.Name), bits
+	case *ast.ParenExpr:
+		return p.evalConst(e.X, iota)
+	case *ast.UnaryExpr:
+		x, bits := p.evalConst(e.X, iota)
+		if x.Kind() == constant.Unknown {
+			return x, 0
+		}
+		return constant.UnaryOp(e.Op, x, bits), bits
+	case *ast.BinaryExpr:
+		x, xbits := p.evalConst(e.X, iota)
+		y, ybits := p.evalConst(e.Y, iota)
+		if x.Kind() == constant.Unknown || y.Kind() == constant.Unknown {
+			return constant.MakeUnknown(), 0
+		}
+		switch e.Op {
+		case token.SHL, token.SHR:
+			s, ok := constant.Uint64Val(constant.ToInt(y))
+			if !ok {
+				return constant.MakeUnknown(), 0
+			}
+			return constant.Shift(constant.ToInt(x), e.Op, uint(s)), xbits
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+			return constant.MakeBool(constant.Compare(x, e.Op, y)), 0
+		case token.QUO:
+			if x.Kind() == constant.Int && y.Kind() == constant.Int {
+				// integer division
+				return constant.BinaryOp(x, token.QUO_ASSIGN, y), xbits | ybits
+			}
+		}
+		return constant.BinaryOp(x, e.Op, y), xbits | ybits
+	case *ast.CallExpr:
+		fun, ok := e.Fun.(*ast.Ident)
+		if !ok || len(e.Args) != 1 {
+			break
+		}
+		x, bits := p.evalConst(e.Args[0], iota)
+		if x.Kind() == constant.Unknown {
+			break
+		}
+		if fun.Name == "len" {
+			if x.Kind() != constant.String {
+				break
+			}
+			return constant.MakeInt64(int64(len(constant.StringVal(x)))), 0
+		}
+		// type conversion
+		if b := unsignedBits(fun); b > 0 {
+			bits = b
+		}
+		return convertConst(x, p.basicType(fun.Name, 0)), bits
+	}
+	return constant.MakeUnknown(), 0
+}
+
+// basicType returns the name of the predeclared basic type that is the
+// underlying type of the type called name, following the types declared in
+// p.typedef. An empty string is returned if name is not a basic type, or is
+// not declared in the package. depth limits the length of the chain of
+// declarations that is followed.
+func (p *tagParser) basicType(name string, depth int) string {
+	switch name {
+	case "bool", "string", "int", "int8", "int16", "int32", "int64", "uint",
+		"uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune",
+		"float32", "float64", "complex64", "complex128":
+		return name
+	}
+	typ, ok := p.typedef[name].(*ast.Ident)
+	if !ok || depth > 10 {
+		return ""
+	}
+	return p.basicType(typ.Name, depth+1)
+}
+
+// convertConst returns the value of constant x converted to the basic type
+// typ, or an unknown value if the conversion is not a constant conversion. An
+// integer converted to a string becomes the UTF-8 encoding of the rune.
+func convertConst(x constant.Value, typ string) constant.Value {
+	switch typ {
+	case "bool":
+		if x.Kind() == constant.Bool {
+			return x
+		}
+	case "string":
+		switch x.Kind() {
+		case constant.String:
+			return x
+		case constant.Int:
+			r := rune(utf8.RuneError)
+			if n, ok := constant.Int64Val(x); ok && utf8.ValidRune(rune(n)) && int64(rune(n)) == n {
+				r = rune(n)
+			}
+			return constant.MakeString(string(r))
+		}
+	case "float32", "float64":
+		if v := constant.ToFloat(x); v.Kind() == constant.Float || v.Kind() == constant.Int {
+			return v
+		}
+	case "complex64", "complex128":
+		if v := constant.ToComplex(x); v.Kind() != constant.Unknown {
+			return v
+		}
+	case "":
+	default:
+		if v := constant.ToInt(x); v.Kind() == constant.Int {
+			return v
+		}
+	}
+	return constant.MakeUnknown()
+}
+
+// unsignedBits returns the size in bits of typ if it is a predeclared unsigned
+// integer type, or 0 otherwise.
+func unsignedBits(typ ast.Expr) uint {
+	id, ok := typ.(*ast.Ident)
+	if !ok {
+		return 0
+	}
+	switch id.Name {
+	case "uint8", "byte":
+		return 8
+	case "uint16":
+		return 16
+	case "uint32":
+		return 32
+	case "uint64", "uint", "uintptr":
+		return 64
+	}
+	return 0
+}
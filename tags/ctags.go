@@ -0,0 +1,74 @@
+package tags
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// MetaTag is a pseudo tag, it contains information about the tags file
+// instead of a symbol.
+type MetaTag struct {
+	Name    string // name without the !_ prefix
+	Value   string
+	Comment string
+}
+
+// The tags file format string representation of this meta tag.
+func (m MetaTag) String() string {
+	s := fmt.Sprintf("!_%s\t%s", m.Name, m.Value)
+	if len(m.Comment) > 0 {
+		s = fmt.Sprintf("%s\t/%s/", s, m.Comment)
+	}
+	return s
+}
+
+// WriteOptions configures the output of the writers.
+type WriteOptions struct {
+	// Sort sorts the tags by name.
+	Sort bool
+
+	// Fields contains the fields selected with ParseFields, it determines
+	// how the kind of a tag is written in the tags file format. If Fields is
+	// nil, the kind letter is written.
+	Fields FieldSet
+}
+
+// kind returns the kind field of a tag of type t in the tags file format.
+func (o WriteOptions) kind(t TagType) string {
+	if o.Fields == nil {
+		return string(t)
+	}
+	return o.Fields.Kind(t)
+}
+
+// WriteCtags writes the meta tags followed by tags to w in the tags file
+// format.
+func WriteCtags(w io.Writer, metaTags []MetaTag, tags []Tag, opts WriteOptions) error {
+	return MergeCtags(w, metaTags, tags, nil, opts)
+}
+
+// MergeCtags writes the meta tags, tags and the tag lines of an existing tags
+// file in lines to w in the tags file format. All tags are sorted together if
+// opts.Sort is set.
+func MergeCtags(w io.Writer, metaTags []MetaTag, tags []Tag, lines []string, opts WriteOptions) error {
+	output := make([]string, 0, len(metaTags)+len(tags)+len(lines))
+	for _, m := range metaTags {
+		output = append(output, m.String())
+	}
+	for _, tag := range tags {
+		output = append(output, tag.format(opts.kind(tag.Type)))
+	}
+	output = append(output, lines...)
+
+	if opts.Sort {
+		sort.Sort(sort.StringSlice(output))
+	}
+
+	for _, s := range output {
+		if _, err := fmt.Fprintln(w, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package tags
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMetaTagString(t *testing.T) {
+	var tests = []struct {
+		metaTag MetaTag
+		s       string
+	}{
+		{MetaTag{"TAG_FILE_FORMAT", "2", ""}, "!_TAG_FILE_FORMAT\t2"},
+		{MetaTag{"TAG_FILE_SORTED", "1", "0=unsorted, 1=sorted"}, "!_TAG_FILE_SORTED\t1\t/0=unsorted, 1=sorted/"},
+	}
+
+	for _, test := range tests {
+		if s := test.metaTag.String(); s != test.s {
+			t.Errorf("MetaTag.String()\n  is:%s\nwant:%s", s, test.s)
+		}
+	}
+}
+
+func TestWriteCtags(t *testing.T) {
+	metaTags := []MetaTag{{"TAG_FILE_FORMAT", "2", ""}}
+	tags := []Tag{
+		NewTag("b", "file.go", 2, Function),
+		NewTag("a", "file.go", 1, Package),
+	}
+
+	var tests = []struct {
+		opts     WriteOptions
+		expected string
+	}{
+		{WriteOptions{}, "!_TAG_FILE_FORMAT\t2\nb\tfile.go\t2;\"\tf\tline:2\na\tfile.go\t1;\"\tp\tline:1\n"},
+		{WriteOptions{Sort: true}, "!_TAG_FILE_FORMAT\t2\na\tfile.go\t1;\"\tp\tline:1\nb\tfile.go\t2;\"\tf\tline:2\n"},
+		{WriteOptions{Sort: true, Fields: FieldSet{KindName: true}}, "!_TAG_FILE_FORMAT\t2\na\tfile.go\t1;\"\tpackage\tline:1\nb\tfile.go\t2;\"\tfunction\tline:2\n"},
+	}
+
+	for _, test := range tests {
+		var b bytes.Buffer
+		if err := WriteCtags(&b, metaTags, tags, test.opts); err != nil {
+			t.Fatalf("unexpected error from WriteCtags: %s", err)
+		}
+		if b.String() != test.expected {
+			t.Errorf("WriteCtags(%+v)\n  is:%q\nwant:%q", test.opts, b.String(), test.expected)
+		}
+	}
+}
+
+func TestMergeCtags(t *testing.T) {
+	metaTags := []MetaTag{{"TAG_FILE_FORMAT", "2", ""}}
+	tags := []Tag{NewTag("B", "b.go", 1, Function)}
+	lines := []string{"C\tc.go\t1;\"\tf\tline:1", "A\ta.go\t1;\"\tf\tline:1"}
+
+	var b bytes.Buffer
+	if err := MergeCtags(&b, metaTags, tags, lines, WriteOptions{Sort: true}); err != nil {
+		t.Fatalf("unexpected error from MergeCtags: %s", err)
+	}
+
+	expected := "!_TAG_FILE_FORMAT\t2\n" +
+		"A\ta.go\t1;\"\tf\tline:1\n" +
+		"B\tb.go\t1;\"\tf\tline:1\n" +
+		"C\tc.go\t1;\"\tf\tline:1\n"
+	if b.String() != expected {
+		t.Errorf("MergeCtags()\n  is:%q\nwant:%q", b.String(), expected)
+	}
+}
@@ -0,0 +1,100 @@
+package tags
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// directives contains the directives for which directive tags are created.
+var directives = []string{"go:linkname", "go:embed", "go:generate", "export"}
+
+// parseDirectives creates a directive tag for each //go:linkname, //go:embed,
+// //go:generate and //export directive in f. The tags of the directives that
+// apply to a declaration, i.e. all but //go:generate, are named after the
+// declared symbol. A //go:generate tag is named after the command it runs.
+func (p *tagParser) parseDirectives(f *ast.File) {
+	symbols := directiveSymbols(f)
+	for _, group := range f.Comments {
+		for _, c := range group.List {
+			directive, args, ok := parseDirective(c.Text)
+			if !ok {
+				continue
+			}
+
+			var name, target string
+			switch directive {
+			case "go:linkname":
+				// //go:linkname localname [importpath.name]
+				fields := strings.Fields(args)
+				if len(fields) == 0 {
+					continue
+				}
+				name = fields[0]
+				target = strings.Join(fields[1:], " ")
+			case "go:embed":
+				name, target = symbols[group], args
+			case "go:generate":
+				fields := strings.Fields(args)
+				if len(fields) == 0 {
+					continue
+				}
+				name, target = fields[0], args
+			case "export":
+				name, target = symbols[group], args
+			}
+			if name == "" {
+				continue
+			}
+
+			tag := p.createTag(name, c.Pos(), token.NoPos, Directive)
+			tag.Fields[DirectiveField] = directive
+			tag.Fields[Target] = target
+			p.tags = append(p.tags, tag)
+		}
+	}
+}
+
+// parseDirective returns the directive and its arguments if comment text is
+// one of the directives in directives.
+func parseDirective(text string) (directive, args string, ok bool) {
+	if !strings.HasPrefix(text, "//") {
+		return "", "", false
+	}
+	text = text[2:]
+	for _, d := range directives {
+		if text == d {
+			return d, "", true
+		}
+		if strings.HasPrefix(text, d+" ") || strings.HasPrefix(text, d+"\t") {
+			return d, strings.TrimSpace(text[len(d):]), true
+		}
+	}
+	return "", "", false
+}
+
+// directiveSymbols returns the name of the function or variable declared
+// after each doc comment in f, which is the symbol a //go:embed or //export
+// directive in that comment applies to.
+func directiveSymbols(f *ast.File) map[*ast.CommentGroup]string {
+	symbols := make(map[*ast.CommentGroup]string)
+	for _, d := range f.Decls {
+		switch decl := d.(type) {
+		case *ast.FuncDecl:
+			if decl.Doc != nil {
+				symbols[decl.Doc] = decl.Name.Name
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				vs, ok := spec.(*ast.ValueSpec)
+				if !ok || len(vs.Names) == 0 {
+					continue
+				}
+				if doc := specDoc(decl, vs.Doc); doc != nil {
+					symbols[doc] = vs.Names[0].Name
+				}
+			}
+		}
+	}
+	return symbols
+}
@@ -0,0 +1,61 @@
+package tags
+
+import (
+	"go/ast"
+	"go/doc"
+	"strings"
+)
+
+// setDoc sets the doc comment of tag to the text of comment, and its doc field
+// to the synopsis of the comment. If the comment contains a deprecation
+// notice, the deprecated field is set as well. Nothing is set if comment is
+// nil.
+func setDoc(tag *Tag, comment *ast.CommentGroup) {
+	if comment == nil {
+		return
+	}
+
+	tag.Doc = comment.Text()
+	if synopsis := docSynopsis(tag.Doc); synopsis != "" {
+		tag.Fields[DocField] = synopsis
+	}
+	if isDeprecated(tag.Doc) {
+		tag.Fields[Deprecated] = "true"
+	}
+}
+
+// docSynopsis returns the first sentence of doc comment text, following the
+// rules of go/doc.
+func docSynopsis(text string) string {
+	return new(doc.Package).Synopsis(text)
+}
+
+// isDeprecated reports whether doc comment text contains a paragraph starting
+// with "Deprecated: ".
+func isDeprecated(text string) bool {
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		if strings.HasPrefix(strings.TrimSpace(paragraph), "Deprecated: ") {
+			return true
+		}
+	}
+	return false
+}
+
+// specDoc returns comment, the doc comment of a spec in decl. If the spec has
+// no doc comment of its own, the doc comment of decl is used when it is the only
+// spec of an unparenthesized declaration.
+func specDoc(decl *ast.GenDecl, comment *ast.CommentGroup) *ast.CommentGroup {
+	if comment == nil && !decl.Lparen.IsValid() {
+		return decl.Doc
+	}
+	return comment
+}
+
+// fieldDoc returns the doc comment of field f, or its line comment if it has
+// no doc comment.
+func fieldDoc(f *ast.Field) *ast.CommentGroup {
+	if f.Doc != nil {
+		return f.Doc
+	}
+	return f.Comment
+}
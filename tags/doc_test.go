@@ -0,0 +1,116 @@
+package tags
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseDoc(t *testing.T) {
+	tags, err := Parse("testdata/doc.go", Options{})
+	if err != nil {
+		t.Fatalf("unexpected error from Parse: %s", err)
+	}
+
+	expected := map[string]struct {
+		doc        string
+		deprecated bool
+	}{
+		"Test":         {"Package Test is used to test doc comments.", false},
+		"Server":       {"Server serves requests.", false},
+		"Addr":         {"Addr is the address to listen on.", false},
+		"Port":         {"port number to listen on", false},
+		"Start":        {"Start starts the server.", true},
+		"MaxConns":     {"MaxConns is the maximum number of connections.", false},
+		"MinConns":     {"", false},
+		"Timeout":      {"Timeout is the default timeout in seconds.", false},
+		"Handler":      {"Handler handles a request.", false},
+		"Handle":       {"Handle handles r.", false},
+		"undocumented": {"", false},
+	}
+
+	if len(tags) != len(expected) {
+		t.Fatalf("len(tags) == %d, want %d", len(tags), len(expected))
+	}
+	for _, tag := range tags {
+		want, ok := expected[tag.Name]
+		if !ok {
+			t.Errorf("unexpected tag %s", tag.Name)
+			continue
+		}
+		if doc := tag.Fields[DocField]; doc != want.doc {
+			t.Errorf("[%s] doc field = %q, want %q", tag.Name, doc, want.doc)
+		}
+		if _, deprecated := tag.Fields[Deprecated]; deprecated != want.deprecated {
+			t.Errorf("[%s] deprecated = %t, want %t", tag.Name, deprecated, want.deprecated)
+		}
+		if want.doc != "" && !strings.HasPrefix(tag.Doc, want.doc) {
+			t.Errorf("[%s] Doc = %q, want it to start with %q", tag.Name, tag.Doc, want.doc)
+		}
+	}
+}
+
+func TestDocFields(t *testing.T) {
+	parseStart := func() Tag {
+		tags, err := Parse("testdata/doc.go", Options{})
+		if err != nil {
+			t.Fatalf("unexpected error from Parse: %s", err)
+		}
+		for _, tag := range tags {
+			if tag.Name == "Start" {
+				return tag
+			}
+		}
+		t.Fatalf("tag Start not found")
+		return Tag{}
+	}
+
+	set, err := ParseFields("-{doc}{deprecated}")
+	if err != nil {
+		t.Fatalf("unexpected error from ParseFields: %s", err)
+	}
+	start := parseStart()
+	set.Apply(start)
+	if _, ok := start.Fields[DocField]; ok {
+		t.Errorf("expected doc field to be excluded with -{doc}")
+	}
+	if _, ok := start.Fields[Deprecated]; ok {
+		t.Errorf("expected deprecated field to be excluded with -{deprecated}")
+	}
+
+	start = parseStart()
+	DefaultFields().Apply(start)
+
+	var b bytes.Buffer
+	if err := WriteJSON(&b, nil, []Tag{start}, WriteOptions{}); err != nil {
+		t.Fatalf("unexpected error from WriteJSON: %s", err)
+	}
+	for _, want := range []string{
+		`"doc":"Start starts the server."`,
+		`"docText":"Start starts the server.\n\nDeprecated: Use Run instead.\n"`,
+		`"deprecated":true`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("expected JSON output to contain %s, got:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestIsDeprecated(t *testing.T) {
+	var tests = []struct {
+		text string
+		want bool
+	}{
+		{"Start starts the server.\n\nDeprecated: Use Run instead.\n", true},
+		{"Deprecated: Use Run instead.\n", true},
+		{"Start is not Deprecated: really.\n", false},
+		{"Start starts the server.\nDeprecated: not a paragraph.\n", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		if got := isDeprecated(test.text); got != test.want {
+			t.Errorf("isDeprecated(%q) = %t, want %t", test.text, got, test.want)
+		}
+	}
+}
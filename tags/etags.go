@@ -0,0 +1,64 @@
+package tags
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// WriteEtags writes tags to w in the Emacs TAGS file format. Each file gets its
+// own section, starting with a form feed and a header containing the file name
+// and the size of the section. The meta tags have no representation in this
+// format and are ignored. The tags of each file are written in the order in
+// which they appear in the file, so opts is not used.
+func WriteEtags(w io.Writer, metaTags []MetaTag, tags []Tag, opts WriteOptions) error {
+	var files []string
+	sections := make(map[string][]Tag)
+	for _, t := range tags {
+		if _, ok := sections[t.File]; !ok {
+			files = append(files, t.File)
+		}
+		sections[t.File] = append(sections[t.File], t)
+	}
+
+	for _, file := range files {
+		section := sections[file]
+		sort.Stable(byOffset(section))
+
+		var b bytes.Buffer
+		for _, t := range section {
+			fmt.Fprintf(&b, "%s\x7f%s\x01%s,%d\n", etagsText(t), t.Name, tagLine(t), t.Offset-(t.Column-1))
+		}
+
+		if _, err := fmt.Fprintf(w, "\x0c\n%s,%d\n", file, b.Len()); err != nil {
+			return err
+		}
+		if _, err := b.WriteTo(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// etagsText returns the text used to search for tag t, which is its source
+// line up to and including the tag name. If the name cannot be found in the
+// source line, the entire line is returned.
+func etagsText(t Tag) string {
+	col := t.Column - 1
+	if col < 0 || col > len(t.Source) {
+		return t.Source
+	}
+	if idx := strings.Index(t.Source[col:], t.Name); idx >= 0 {
+		return t.Source[:col+idx+len(t.Name)]
+	}
+	return strings.TrimRight(t.Source, " \t")
+}
+
+// byOffset implements sort.Interface to sort tags by their offset in a file.
+type byOffset []Tag
+
+func (t byOffset) Len() int           { return len(t) }
+func (t byOffset) Less(i, j int) bool { return t[i].Offset < t[j].Offset }
+func (t byOffset) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
@@ -0,0 +1,46 @@
+package tags
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteEtags(t *testing.T) {
+	tags, err := Parse("testdata/const.go", Options{})
+	if err != nil {
+		t.Fatalf("unexpected error from Parse: %s", err)
+	}
+
+	var b bytes.Buffer
+	if err := WriteEtags(&b, nil, tags, WriteOptions{}); err != nil {
+		t.Fatalf("unexpected error from WriteEtags: %s", err)
+	}
+
+	expected := "\x0c\ntestdata/const.go,131\n" +
+		"package Test\x7fTest\x011,0\n" +
+		"const Constant\x7fConstant\x013,14\n" +
+		"const OtherConst\x7fOtherConst\x014,46\n" +
+		"\tA\x7fA\x017,82\n" +
+		"\tB\x7fB\x018,95\n" +
+		"\tB, C\x7fC\x018,95\n" +
+		"\t_, D\x7fD\x019,112\n"
+
+	if b.String() != expected {
+		t.Errorf("WriteEtags()\n  is:%q\nwant:%q", b.String(), expected)
+	}
+}
+
+func TestEtagsText(t *testing.T) {
+	tag := NewTag("Method", "filename", 3, Method)
+	tag.Column = 1
+	tag.Source = "func (s *Struct) Method() {  "
+
+	if s := etagsText(tag); s != "func (s *Struct) Method" {
+		t.Errorf("etagsText() = %q, want %q", s, "func (s *Struct) Method")
+	}
+
+	tag.Name = "Struct.Method"
+	if s := etagsText(tag); s != "func (s *Struct) Method() {" {
+		t.Errorf("etagsText() = %q, want %q", s, "func (s *Struct) Method() {")
+	}
+}
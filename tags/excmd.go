@@ -0,0 +1,98 @@
+package tags
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Excmd is the kind of ex command used as the address of a tag.
+type Excmd string
+
+// Ex commands.
+const (
+	ExcmdNumber  Excmd = "number"  // line number
+	ExcmdPattern Excmd = "pattern" // search pattern
+	ExcmdMixed   Excmd = "mixed"   // line number for C #defines, search pattern for all others
+	ExcmdCombine Excmd = "combine" // line number followed by search pattern
+)
+
+// patternLengthLimit is the maximum number of characters of the source line
+// used in a search pattern. Longer lines are truncated.
+const patternLengthLimit = 96
+
+// Valid reports whether e is a known ex command.
+func (e Excmd) Valid() bool {
+	switch e {
+	case ExcmdNumber, ExcmdPattern, ExcmdMixed, ExcmdCombine:
+		return true
+	}
+	return false
+}
+
+// Address returns the address of tag using ex command e. The address of tag
+// should be its line number. If the source line of tag is not known, the line
+// number is used regardless of e. As in ctags, ExcmdMixed only uses line
+// numbers for C #defines, so Go tags always get a search pattern.
+func (e Excmd) Address(tag Tag) string {
+	if e == ExcmdNumber || len(tag.Source) == 0 {
+		return tag.Address
+	}
+
+	pattern, _ := searchPattern(tag.Source)
+	switch e {
+	case ExcmdPattern, ExcmdMixed:
+		return pattern
+	case ExcmdCombine:
+		return tag.Address + ";" + pattern
+	}
+	return tag.Address
+}
+
+// searchPattern returns an anchored search pattern that finds source, such as
+// /^func main() {$/. Backslashes and slashes are escaped. Trailing whitespace
+// is removed, and lines longer than patternLengthLimit are truncated. In both
+// cases the pattern is no longer anchored at the end of the line, so complete
+// is false.
+func searchPattern(source string) (pattern string, complete bool) {
+	line := strings.TrimRight(source, " \t")
+	complete = len(line) == len(source)
+	if utf8.RuneCountInString(line) > patternLengthLimit {
+		line = string([]rune(line)[:patternLengthLimit])
+		complete = false
+	}
+
+	var b strings.Builder
+	b.WriteString("/^")
+	for _, c := range line {
+		if c == '\\' || c == '/' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	if complete {
+		b.WriteByte('$')
+	}
+	b.WriteByte('/')
+	return b.String(), complete
+}
+
+// addressLine returns the line number in address, or an empty string if
+// address is a search pattern without line number.
+func addressLine(address string) string {
+	if idx := strings.IndexByte(address, ';'); idx >= 0 {
+		address = address[:idx]
+	}
+	if len(address) == 0 || strings.Trim(address, "0123456789") != "" {
+		return ""
+	}
+	return address
+}
+
+// addressPattern returns the search pattern in address, or an empty string if
+// address does not contain a search pattern.
+func addressPattern(address string) string {
+	if idx := strings.IndexByte(address, '/'); idx >= 0 {
+		return address[idx:]
+	}
+	return ""
+}
@@ -0,0 +1,90 @@
+package tags
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSearchPattern(t *testing.T) {
+	long := "var x = \"" + strings.Repeat("a", 100) + "\""
+
+	var tests = []struct {
+		source   string
+		pattern  string
+		complete bool
+	}{
+		{"func main() {", "/^func main() {$/", true},
+		{"\tpath = \"a/b\\c\"", "/^\tpath = \"a\\/b\\\\c\"$/", true},
+		{"type T struct {  \t", "/^type T struct {/", false},
+		{"const Dollar = \"$\"", "/^const Dollar = \"$\"$/", true},
+		{long, "/^" + long[:patternLengthLimit] + "/", false},
+		{"# ü" + strings.Repeat("ä", 100), "/^# ü" + strings.Repeat("ä", patternLengthLimit-3) + "/", false},
+	}
+
+	for _, test := range tests {
+		pattern, complete := searchPattern(test.source)
+		if pattern != test.pattern || complete != test.complete {
+			t.Errorf("searchPattern(%q) = %q, %t, want %q, %t", test.source, pattern, complete, test.pattern, test.complete)
+		}
+	}
+}
+
+func TestExcmdAddress(t *testing.T) {
+	tag := NewTag("main", "main.go", 5, Function)
+	tag.Source = "func main() {"
+	trailing := NewTag("T", "main.go", 7, Type)
+	trailing.Source = "type T int "
+	unknown := NewTag("U", "main.go", 9, Type)
+
+	var tests = []struct {
+		tag     Tag
+		excmd   Excmd
+		address string
+	}{
+		{tag, ExcmdNumber, "5"},
+		{tag, ExcmdPattern, "/^func main() {$/"},
+		{tag, ExcmdMixed, "/^func main() {$/"},
+		{tag, ExcmdCombine, "5;/^func main() {$/"},
+		{trailing, ExcmdPattern, "/^type T int/"},
+		{trailing, ExcmdMixed, "/^type T int/"},
+		{unknown, ExcmdPattern, "9"},
+	}
+
+	for _, test := range tests {
+		if address := test.excmd.Address(test.tag); address != test.address {
+			t.Errorf("Excmd(%s).Address(%s) = %q, want %q", test.excmd, test.tag.Name, address, test.address)
+		}
+	}
+}
+
+func TestAddressLine(t *testing.T) {
+	var tests = []struct {
+		address string
+		line    string
+		pattern string
+	}{
+		{"12", "12", ""},
+		{"/^func main() {$/", "", "/^func main() {$/"},
+		{"12;/^func main() {$/", "12", "/^func main() {$/"},
+	}
+
+	for _, test := range tests {
+		if line := addressLine(test.address); line != test.line {
+			t.Errorf("addressLine(%q) = %q, want %q", test.address, line, test.line)
+		}
+		if pattern := addressPattern(test.address); pattern != test.pattern {
+			t.Errorf("addressPattern(%q) = %q, want %q", test.address, pattern, test.pattern)
+		}
+	}
+}
+
+func TestExcmdValid(t *testing.T) {
+	for _, excmd := range []Excmd{ExcmdNumber, ExcmdPattern, ExcmdMixed, ExcmdCombine} {
+		if !excmd.Valid() {
+			t.Errorf("expected %s to be valid", excmd)
+		}
+	}
+	if Excmd("regex").Valid() {
+		t.Error("expected regex to be invalid")
+	}
+}
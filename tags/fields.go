@@ -0,0 +1,222 @@
+package tags
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FieldSet is a set of extension fields to include in a tag.
+type FieldSet map[TagField]bool
+
+// Includes tests whether the given field is included in the set.
+func (f FieldSet) Includes(field TagField) bool {
+	b, ok := f[field]
+	return ok && b
+}
+
+// ErrInvalidFields is an error returned when attempting to parse invalid
+// fields.
+type ErrInvalidFields struct {
+	Fields string
+}
+
+func (e ErrInvalidFields) Error() string {
+	return fmt.Sprintf("invalid fields: %s", e.Fields)
+}
+
+// Fields that can be selected with ParseFields, but do not appear as
+// extension fields in a tag.
+const (
+	Kind     TagField = "kind"     // kind letter
+	KindName TagField = "kindName" // kind long name
+	KindKey  TagField = "kindKey"  // prefix the kind with "kind:"
+)
+
+// fieldInfo describes a field that can be selected with ParseFields.
+type fieldInfo struct {
+	letter      byte       // letter of the field, 0 if it only has a long name
+	name        TagField   // long name of the field
+	fields      []TagField // tag fields controlled by this field
+	description string
+	enabled     bool // included by default
+}
+
+// fieldTable contains all fields that can be selected with ParseFields.
+var fieldTable = []fieldInfo{
+	{'a', Access, []TagField{Access}, "Access (or export) of class members", true},
+	{'e', End, []TagField{End}, "End lines of various items", false},
+	{'f', FileScope, []TagField{FileScope}, "File-restricted scoping", false},
+	{'k', Kind, nil, "Kind of tag as a single letter", true},
+	{'K', KindName, nil, "Kind of tag as full name", false},
+	{'l', Language, []TagField{Language}, "Language of input file containing tag", false},
+	{'n', Line, []TagField{Line}, "Line number of tag definition", true},
+	{'r', Roles, []TagField{Roles}, "Roles of the tag, e.g. def, call or goto", true},
+	{'s', Scope, []TagField{Scope, ReceiverType, InterfaceType, FunctionScope}, "Scope of tag definition", true},
+	{'S', Signature, []TagField{Signature}, "Signature of routine (e.g. prototype or parameter list)", true},
+	{'t', TypeField, []TagField{TypeField}, "Type and name of a variable or typedef", true},
+	{'z', KindKey, nil, `Include the "kind:" key in kind field`, false},
+	{0, ColumnField, []TagField{ColumnField}, "Column number of tag definition", false},
+	{0, TypeParams, []TagField{TypeParams}, "Type parameters of generic types and functions", true},
+	{0, Build, []TagField{Build}, "Build constraint of the file containing the tag", true},
+	{0, Implements, []TagField{Implements}, "Interfaces implemented by a type", true},
+	{0, ImplementedBy, []TagField{ImplementedBy}, "Types implementing an interface", true},
+	{0, DocField, []TagField{DocField}, "First sentence of the doc comment", true},
+	{0, Deprecated, []TagField{Deprecated}, "Whether the doc comment contains a deprecation notice", true},
+	{0, StructTag, []TagField{StructTag}, "Struct tag of a field", true},
+	{0, Value, []TagField{Value}, "Value of a constant", true},
+	{0, DirectiveField, []TagField{DirectiveField, Target}, "Directive of a directive tag and its target", true},
+}
+
+// lookupField returns the field with letter c or long name name.
+func lookupField(c byte, name string) (fieldInfo, bool) {
+	for _, info := range fieldTable {
+		if (c != 0 && info.letter == c) || (name != "" && string(info.name) == name) {
+			return info, true
+		}
+	}
+	return fieldInfo{}, false
+}
+
+// DefaultFields returns the set of fields that are included by default.
+func DefaultFields() FieldSet {
+	set := FieldSet{}
+	for _, info := range fieldTable {
+		if info.enabled {
+			set[info.name] = true
+		}
+	}
+	return set
+}
+
+// ParseFields parses fields using the universal-ctags --fields syntax. Fields
+// are specified by their letter or by their long name enclosed in braces,
+// e.g. {signature}. A + or - prefix adds or removes the fields that follow
+// from the default fields, otherwise they replace the default fields. A *
+// selects all fields.
+func ParseFields(fields string) (FieldSet, error) {
+	set := DefaultFields()
+	if fields == "" {
+		return set, nil
+	}
+
+	if fields[0] != '+' && fields[0] != '-' {
+		set = FieldSet{}
+	}
+
+	enable := true
+	for i := 0; i < len(fields); i++ {
+		switch c := fields[i]; c {
+		case '+':
+			enable = true
+		case '-':
+			enable = false
+		case '*':
+			for _, info := range fieldTable {
+				set[info.name] = enable
+			}
+		case '{':
+			end := strings.IndexByte(fields[i:], '}')
+			if end < 0 {
+				return FieldSet{}, ErrInvalidFields{fields}
+			}
+			info, ok := lookupField(0, fields[i+1:i+end])
+			if !ok {
+				return FieldSet{}, ErrInvalidFields{fields}
+			}
+			set[info.name] = enable
+			i += end
+		default:
+			info, ok := lookupField(c, "")
+			if !ok {
+				return FieldSet{}, ErrInvalidFields{fields}
+			}
+			set[info.name] = enable
+		}
+	}
+	return set, nil
+}
+
+// Apply adds the fields that are not set by the parser to tag and removes the
+// fields from tag that are not included in f.
+func (f FieldSet) Apply(tag Tag) {
+	if f.Includes(Language) {
+		tag.Fields[Language] = "Go"
+	}
+	if f.Includes(End) && tag.EndLine > 0 {
+		tag.Fields[End] = strconv.Itoa(tag.EndLine)
+	}
+	if f.Includes(ColumnField) && tag.Column > 0 {
+		tag.Fields[ColumnField] = strconv.Itoa(tag.Column)
+	}
+	if f.Includes(FileScope) && tag.Type == Import {
+		// imports are the only file scoped symbols in Go
+		tag.Fields[FileScope] = ""
+	}
+
+	for _, info := range fieldTable {
+		if !f.Includes(info.name) {
+			for _, field := range info.fields {
+				delete(tag.Fields, field)
+			}
+		}
+	}
+}
+
+// Kind returns the kind field of a tag of type t, as selected in f. If the
+// kind is not included, an empty string is returned.
+func (f FieldSet) Kind(t TagType) string {
+	var kind string
+	if f.Includes(KindName) {
+		kind = t.Name()
+	} else if f.Includes(Kind) {
+		kind = string(t)
+	} else {
+		return ""
+	}
+
+	if f.Includes(KindKey) {
+		kind = "kind:" + kind
+	}
+	return kind
+}
+
+// Descriptions returns a meta tag with the description of each included
+// extension field.
+func (f FieldSet) Descriptions() []MetaTag {
+	var metaTags []MetaTag
+	for _, info := range fieldTable {
+		if f.Includes(info.name) {
+			metaTags = append(metaTags, MetaTag{"TAG_FIELD_DESCRIPTION", string(info.name), info.description})
+		}
+	}
+	return metaTags
+}
+
+// ParseExtras parses the extra tags to include. The q extra adds tags
+// with names qualified by their package and scope, the Q extra adds tags with
+// names qualified by the import path of their package and their scope. The l
+// extra adds tags for the symbols declared inside function bodies, the r extra
+// adds reference tags for the uses of the symbols declared in the package and
+// the k extra adds tags for the json, yaml and db keys in struct tags.
+func ParseExtras(symbols string) (FieldSet, error) {
+	set := FieldSet{}
+	for _, c := range symbols {
+		switch c {
+		case '+':
+		case 'q':
+			set[ExtraTags] = true
+		case 'Q':
+			set[ExtraImportPathTags] = true
+		case 'l':
+			set[LocalTags] = true
+		case 'r':
+			set[ReferenceTags] = true
+		case 'k':
+			set[KeyTags] = true
+		default:
+			return FieldSet{}, ErrInvalidFields{symbols}
+		}
+	}
+	return set, nil
+}
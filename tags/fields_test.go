@@ -0,0 +1,126 @@
+package tags
+
+import (
+	"testing"
+)
+
+func TestParseFieldsEmpty(t *testing.T) {
+	_, err := ParseFields("")
+	if err != nil {
+		t.Fatalf("unexpected error from ParseFields: %s", err)
+	}
+}
+
+func TestParseFieldsLanguage(t *testing.T) {
+	set, err := ParseFields("+l")
+	if err != nil {
+		t.Fatalf("unexpected error from ParseFields: %s", err)
+	}
+	if !set.Includes(Language) {
+		t.Fatal("expected set to include Language")
+	}
+}
+
+func TestParseFieldsInvalid(t *testing.T) {
+	_, err := ParseFields("junk")
+	if err == nil {
+		t.Fatal("expected ParseFields to return error")
+	}
+	if _, ok := err.(ErrInvalidFields); !ok {
+		t.Fatalf("expected ParseFields to return error of type ErrInvalidFields, got %T", err)
+	}
+}
+
+func TestParseFieldsSyntax(t *testing.T) {
+	var tests = []struct {
+		fields  string
+		include []TagField
+		exclude []TagField
+	}{
+		{"", []TagField{Access, Kind, Line, Signature, StructTag}, []TagField{Language, KindName}},
+		{"+K-k", []TagField{KindName, Access}, []TagField{Kind}},
+		{"-aS+l", []TagField{Language, Line}, []TagField{Access, Signature}},
+		{"nk", []TagField{Line, Kind}, []TagField{Access, Signature, TypeField}},
+		{"*", []TagField{Access, FileScope, Language, KindKey, Build}, nil},
+		{"-*+n", []TagField{Line}, []TagField{Access, Kind, TypeParams}},
+		{"+{language}-{typeparams}", []TagField{Language, Access}, []TagField{TypeParams}},
+		{"-{structTag}", []TagField{DocField, Deprecated}, []TagField{StructTag}},
+	}
+
+	for _, test := range tests {
+		set, err := ParseFields(test.fields)
+		if err != nil {
+			t.Errorf("[%s] unexpected error from ParseFields: %s", test.fields, err)
+			continue
+		}
+		for _, field := range test.include {
+			if !set.Includes(field) {
+				t.Errorf("[%s] expected set to include %s", test.fields, field)
+			}
+		}
+		for _, field := range test.exclude {
+			if set.Includes(field) {
+				t.Errorf("[%s] expected set not to include %s", test.fields, field)
+			}
+		}
+	}
+}
+
+func TestParseFieldsInvalidSyntax(t *testing.T) {
+	for _, fields := range []string{"+x", "{language", "+{unknown}"} {
+		if _, err := ParseFields(fields); err == nil {
+			t.Errorf("[%s] expected ParseFields to return error", fields)
+		}
+	}
+}
+
+func TestFieldSetApply(t *testing.T) {
+	set, err := ParseFields("+lf-as")
+	if err != nil {
+		t.Fatalf("unexpected error from ParseFields: %s", err)
+	}
+
+	tag := NewTag("fmt", "file.go", 3, Import)
+	tag.Fields[Access] = "private"
+	tag.Fields[ReceiverType] = "T"
+	set.Apply(tag)
+
+	if tag.Fields[Language] != "Go" {
+		t.Errorf("expected language field, got %q", tag.Fields[Language])
+	}
+	if _, ok := tag.Fields[FileScope]; !ok {
+		t.Error("expected file field for import")
+	}
+	for _, field := range []TagField{Access, ReceiverType} {
+		if _, ok := tag.Fields[field]; ok {
+			t.Errorf("expected %s field to be removed", field)
+		}
+	}
+	if s := tag.format(set.Kind(tag.Type)); s != "fmt\tfile.go\t3;\"\ti\tfile:\tlanguage:Go\tline:3" {
+		t.Errorf("unexpected tag output %q", s)
+	}
+}
+
+func TestFieldSetKind(t *testing.T) {
+	var tests = []struct {
+		fields string
+		kind   string
+	}{
+		{"", "f"},
+		{"+K", "function"},
+		{"+z", "kind:f"},
+		{"+Kz", "kind:function"},
+		{"-k", ""},
+	}
+
+	for _, test := range tests {
+		set, err := ParseFields(test.fields)
+		if err != nil {
+			t.Errorf("[%s] unexpected error from ParseFields: %s", test.fields, err)
+			continue
+		}
+		if kind := set.Kind(Function); kind != test.kind {
+			t.Errorf("[%s] kind = %q, want %q", test.fields, kind, test.kind)
+		}
+	}
+}
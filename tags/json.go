@@ -0,0 +1,141 @@
+package tags
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// jsonOutputVersion is the version of the JSON output format, it is written in
+// the JSON_OUTPUT_VERSION pseudo tag.
+const jsonOutputVersion = "0.0"
+
+// scopeFields contains the fields that hold the scope of a tag, together with
+// the kind of scope they represent.
+var scopeFields = []struct {
+	field TagField
+	kind  string
+}{
+	{ReceiverType, "type"},
+	{InterfaceType, "interface"},
+	{FunctionScope, "function"},
+}
+
+// MarshalJSON returns the JSON representation of t. The format is compatible
+// with the JSON output of universal-ctags, with an additional kindLetter key
+// and each extension field stored under its own name. The line and column are
+// always included when known, regardless of the selected fields, and a file
+// scoped tag has a fileScope key. If the doc field is included, the full text
+// of the doc comment is stored under docText.
+func (t Tag) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{
+		"_type":      "tag",
+		"name":       t.Name,
+		"path":       t.File,
+		"kind":       t.Type.Name(),
+		"kindLetter": string(t.Type),
+	}
+	if pattern := addressPattern(t.Address); len(pattern) > 0 {
+		m["pattern"] = pattern
+	}
+	if _, ok := t.Fields[DocField]; ok && len(t.Doc) > 0 {
+		m["docText"] = t.Doc
+	}
+
+	for k, v := range t.Fields {
+		if len(v) == 0 {
+			continue
+		}
+		m[string(k)] = jsonValue(k, v)
+	}
+	if t.Line > 0 {
+		m["line"] = t.Line
+	}
+	if t.Column > 0 {
+		m["column"] = t.Column
+	}
+	if _, ok := t.Fields[FileScope]; ok {
+		m["fileScope"] = true
+	}
+
+	if scope := t.Fields[Scope]; len(scope) > 0 {
+		if idx := strings.IndexByte(scope, ':'); idx >= 0 {
+			m["scope"] = scope[idx+1:]
+			m["scopeKind"] = scope[:idx]
+		}
+	} else {
+		for _, s := range scopeFields {
+			if v := t.Fields[s.field]; len(v) > 0 {
+				m["scope"] = v
+				m["scopeKind"] = s.kind
+				break
+			}
+		}
+	}
+
+	return marshalJSON(m)
+}
+
+// jsonValue converts the value of field to the type it should have in the JSON
+// output. Numeric fields become numbers, boolean fields become booleans, all
+// other fields remain strings.
+func jsonValue(field TagField, value string) interface{} {
+	switch field {
+	case Line, End, ColumnField:
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	case Deprecated:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// MarshalJSON returns the JSON representation of m as a ptag record.
+func (m MetaTag) MarshalJSON() ([]byte, error) {
+	return marshalJSON(map[string]string{
+		"_type":   "ptag",
+		"name":    m.Name,
+		"path":    m.Value,
+		"pattern": m.Comment,
+	})
+}
+
+// marshalJSON returns the JSON encoding of v. Unlike json.Marshal, characters
+// such as < and > are not escaped.
+func marshalJSON(v interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
+
+// WriteJSON writes the meta tags followed by tags to w, one JSON object per
+// line.
+func WriteJSON(w io.Writer, metaTags []MetaTag, tags []Tag, opts WriteOptions) error {
+	metaTags = append([]MetaTag{{"JSON_OUTPUT_VERSION", jsonOutputVersion, "in development"}}, metaTags...)
+	if opts.Sort {
+		sortTags(tags)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, m := range metaTags {
+		if err := enc.Encode(m); err != nil {
+			return err
+		}
+	}
+	for _, t := range tags {
+		if err := enc.Encode(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package tags
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTagMarshalJSON(t *testing.T) {
+	tag := NewTag("Method", "filename", 2, Method)
+	tag.Column = 3
+	tag.Fields[Access] = "public"
+	tag.Fields[ReceiverType] = "Struct"
+	tag.Fields[Signature] = "(a int)"
+	tag.Fields[TypeField] = ""
+
+	expected := `{"_type":"tag","access":"public","column":3,"ctype":"Struct","kind":"method","kindLetter":"m","line":2,"name":"Method","path":"filename","scope":"Struct","scopeKind":"type","signature":"(a int)"}`
+
+	b, err := tag.MarshalJSON()
+	if err != nil {
+		t.Fatalf("unexpected error from MarshalJSON: %s", err)
+	}
+	if string(b) != expected {
+		t.Errorf("Tag.MarshalJSON()\n  is:%s\nwant:%s", b, expected)
+	}
+}
+
+func TestTagMarshalJSONLineColumn(t *testing.T) {
+	tag := NewTag("Function", "filename", 2, Function)
+	tag.Column = 6
+
+	set, err := ParseFields("-{line}")
+	if err != nil {
+		t.Fatalf("unexpected error from ParseFields: %s", err)
+	}
+	set.Apply(tag)
+
+	b, err := tag.MarshalJSON()
+	if err != nil {
+		t.Fatalf("unexpected error from MarshalJSON: %s", err)
+	}
+	for _, s := range []string{`"line":2`, `"column":6`} {
+		if !bytes.Contains(b, []byte(s)) {
+			t.Errorf("expected %s without the line and column fields, got %s", s, b)
+		}
+	}
+}
+
+func TestTagMarshalJSONFileScope(t *testing.T) {
+	tag := NewTag("fmt", "filename", 3, Import)
+	set, err := ParseFields("+f")
+	if err != nil {
+		t.Fatalf("unexpected error from ParseFields: %s", err)
+	}
+	set.Apply(tag)
+
+	b, err := tag.MarshalJSON()
+	if err != nil {
+		t.Fatalf("unexpected error from MarshalJSON: %s", err)
+	}
+	if !bytes.Contains(b, []byte(`"fileScope":true`)) || bytes.Contains(b, []byte(`"file"`)) {
+		t.Errorf("expected fileScope instead of the file field, got %s", b)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var b bytes.Buffer
+	metaTags := []MetaTag{{"TAG_FILE_FORMAT", "2", ""}}
+	tags := []Tag{NewTag("<b>", "filename", 1, Package)}
+
+	if err := WriteJSON(&b, metaTags, tags, WriteOptions{}); err != nil {
+		t.Fatalf("unexpected error from WriteJSON: %s", err)
+	}
+
+	expected := `{"_type":"ptag","name":"JSON_OUTPUT_VERSION","path":"0.0","pattern":"in development"}
+{"_type":"ptag","name":"TAG_FILE_FORMAT","path":"2","pattern":""}
+{"_type":"tag","kind":"package","kindLetter":"p","line":1,"name":"<b>","path":"filename"}
+`
+	if b.String() != expected {
+		t.Errorf("WriteJSON()\n  is:%s\nwant:%s", b.String(), expected)
+	}
+}
@@ -0,0 +1,146 @@
+package tags
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// KindSet is a set of tag types to include in the output.
+type KindSet map[TagType]bool
+
+// Includes tests whether tags of the given type are included in the set.
+func (k KindSet) Includes(t TagType) bool {
+	b, ok := k[t]
+	return ok && b
+}
+
+// ErrInvalidKinds is an error returned when attempting to parse invalid
+// kinds.
+type ErrInvalidKinds struct {
+	Kinds string
+}
+
+func (e ErrInvalidKinds) Error() string {
+	return fmt.Sprintf("invalid kinds: %s", e.Kinds)
+}
+
+// kindInfo describes a kind of tag.
+type kindInfo struct {
+	letter      TagType
+	name        string
+	description string
+	enabled     bool // included by default
+}
+
+// kindTable contains all kinds of tags gotags generates.
+var kindTable = []kindInfo{
+	{Package, "package", "packages", true},
+	{Import, "import", "imports", true},
+	{Constant, "constant", "constants", true},
+	{Variable, "variable", "variables", true},
+	{Type, "type", "types", true},
+	{Interface, "interface", "interfaces", true},
+	{Field, "field", "struct fields", true},
+	{Embedded, "embedded", "embedded types", true},
+	{Method, "method", "methods", true},
+	{Constructor, "constructor", "constructors", true},
+	{Function, "function", "functions", true},
+	{TypeParam, "typeparam", "type parameters", true},
+	{Parameter, "parameter", "function parameters (local)", true},
+	{Label, "label", "labels (local)", true},
+	{Key, "key", "struct tag keys", true},
+	{Directive, "directive", "compiler directives", true},
+}
+
+// lookupKind returns the kind with letter t or long name name.
+func lookupKind(t TagType, name string) (kindInfo, bool) {
+	for _, info := range kindTable {
+		if (t != "" && info.letter == t) || (name != "" && info.name == name) {
+			return info, true
+		}
+	}
+	return kindInfo{}, false
+}
+
+// DefaultKinds returns the set of kinds that are included by default.
+func DefaultKinds() KindSet {
+	set := KindSet{}
+	for _, info := range kindTable {
+		if info.enabled {
+			set[info.letter] = true
+		}
+	}
+	return set
+}
+
+// ParseKinds parses kinds using the universal-ctags --kinds-<LANG> syntax.
+// Kinds are specified by their letter or by their long name enclosed in
+// braces, e.g. {function}. A + or - prefix enables or disables the kinds that
+// follow, otherwise they replace the default kinds. A * selects all kinds.
+func ParseKinds(kinds string) (KindSet, error) {
+	set := DefaultKinds()
+	if kinds == "" {
+		return set, nil
+	}
+
+	if kinds[0] != '+' && kinds[0] != '-' {
+		set = KindSet{}
+	}
+
+	enable := true
+	for i := 0; i < len(kinds); i++ {
+		switch c := kinds[i]; c {
+		case '+':
+			enable = true
+		case '-':
+			enable = false
+		case '*':
+			for _, info := range kindTable {
+				set[info.letter] = enable
+			}
+		case '{':
+			end := strings.IndexByte(kinds[i:], '}')
+			if end < 0 {
+				return KindSet{}, ErrInvalidKinds{kinds}
+			}
+			info, ok := lookupKind("", kinds[i+1:i+end])
+			if !ok {
+				return KindSet{}, ErrInvalidKinds{kinds}
+			}
+			set[info.letter] = enable
+			i += end
+		default:
+			info, ok := lookupKind(TagType(c), "")
+			if !ok {
+				return KindSet{}, ErrInvalidKinds{kinds}
+			}
+			set[info.letter] = enable
+		}
+	}
+	return set, nil
+}
+
+// Filter returns the tags in tags whose type is included in k. The tags are
+// filtered in place.
+func (k KindSet) Filter(tags []Tag) []Tag {
+	filtered := tags[:0]
+	for _, tag := range tags {
+		if k.Includes(tag.Type) {
+			filtered = append(filtered, tag)
+		}
+	}
+	return filtered
+}
+
+// ListKinds writes the letter, long name and description of each kind to w.
+// Kinds that are disabled by default are marked with [off].
+func ListKinds(w io.Writer) {
+	for _, info := range kindTable {
+		off := ""
+		if !info.enabled {
+			off = " [off]"
+		}
+		fmt.Fprintf(w, "%s  %-12s %s%s\n", info.letter, info.name, info.description, off)
+	}
+}
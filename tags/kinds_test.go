@@ -0,0 +1,85 @@
+package tags
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseKinds(t *testing.T) {
+	var tests = []struct {
+		kinds   string
+		include []TagType
+		exclude []TagType
+	}{
+		{"", []TagType{Package, Import, Field, Function, TypeParam}, nil},
+		{"+f+m-i-w", []TagType{Function, Method, Type}, []TagType{Import, Field}},
+		{"fm", []TagType{Function, Method}, []TagType{Import, Type, Package}},
+		{"-*+t", []TagType{Type}, []TagType{Function, Package}},
+		{"{function}{method}", []TagType{Function, Method}, []TagType{Type}},
+		{"-{import}", []TagType{Function}, []TagType{Import}},
+	}
+
+	for _, test := range tests {
+		set, err := ParseKinds(test.kinds)
+		if err != nil {
+			t.Errorf("[%s] unexpected error from ParseKinds: %s", test.kinds, err)
+			continue
+		}
+		for _, kind := range test.include {
+			if !set.Includes(kind) {
+				t.Errorf("[%s] expected set to include %s", test.kinds, kind)
+			}
+		}
+		for _, kind := range test.exclude {
+			if set.Includes(kind) {
+				t.Errorf("[%s] expected set not to include %s", test.kinds, kind)
+			}
+		}
+	}
+}
+
+func TestParseKindsInvalid(t *testing.T) {
+	for _, kinds := range []string{"x", "+f+x", "{function", "{unknown}"} {
+		_, err := ParseKinds(kinds)
+		if err == nil {
+			t.Errorf("[%s] expected ParseKinds to return error", kinds)
+			continue
+		}
+		if _, ok := err.(ErrInvalidKinds); !ok {
+			t.Errorf("[%s] expected ParseKinds to return error of type ErrInvalidKinds, got %T", kinds, err)
+		}
+	}
+}
+
+func TestKindSetFilter(t *testing.T) {
+	set, err := ParseKinds("-iw")
+	if err != nil {
+		t.Fatalf("unexpected error from ParseKinds: %s", err)
+	}
+
+	tags := []Tag{
+		NewTag("Test", "file.go", 1, Package),
+		NewTag("fmt", "file.go", 3, Import),
+		NewTag("Field", "file.go", 6, Field),
+		NewTag("Function", "file.go", 9, Function),
+	}
+	tags = set.Filter(tags)
+
+	if len(tags) != 2 || tags[0].Name != "Test" || tags[1].Name != "Function" {
+		t.Errorf("unexpected filtered tags %v", tags)
+	}
+}
+
+func TestListKinds(t *testing.T) {
+	var b bytes.Buffer
+	ListKinds(&b)
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != len(kindTable) {
+		t.Fatalf("expected %d kinds, got %d", len(kindTable), len(lines))
+	}
+	if want := "f  function     functions"; !strings.Contains(b.String(), want) {
+		t.Errorf("expected kind list to contain %q, got:\n%s", want, b.String())
+	}
+}
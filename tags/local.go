@@ -0,0 +1,166 @@
+package tags
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+// parseLocals creates a tag for each parameter of function f and for each
+// symbol declared in its body, if local tags are selected in p.opts.Extra.
+// The tags have a scope field pointing at f, tag is the tag of f.
+func (p *tagParser) parseLocals(f *ast.FuncDecl, tag Tag) {
+	if !p.opts.Extra.Includes(LocalTags) || f.Body == nil {
+		return
+	}
+
+	scope := scopeOf(tag.Type, tag.Name)
+	if tag.Type == Method {
+		scope = scopeOf(Method, p.qualify(tag.Fields[ReceiverType], tag.Name))
+	}
+	p.parseParams(f.Type, scope)
+	p.parseBody(f.Body, scope)
+}
+
+// parseParams creates a tag for each named parameter and result of function
+// type t, declared in scope.
+func (p *tagParser) parseParams(t *ast.FuncType, scope string) {
+	for _, list := range []*ast.FieldList{t.Params, t.Results} {
+		if list == nil {
+			continue
+		}
+		for _, f := range list.List {
+			for _, n := range f.Names {
+				if n.Name == "_" {
+					continue
+				}
+				tag := p.createTag(n.Name, n.Pos(), token.NoPos, Parameter)
+				tag.Fields[TypeField] = getType(f.Type, true)
+				tag.Fields[Scope] = scope
+				p.tags = append(p.tags, tag)
+			}
+		}
+	}
+}
+
+// parseBody creates a tag for each type, constant, variable, label and named
+// closure declared in function body body, using scope as their scope. Labels
+// get the def role, the branch statements referring to them are reference
+// tags. The symbols declared in the body of a named closure are scoped to the
+// closure, those of anonymous closures to the enclosing function.
+func (p *tagParser) parseBody(body *ast.BlockStmt, scope string) {
+	closures := make(map[*ast.FuncLit]bool)
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch s := n.(type) {
+		case *ast.FuncLit:
+			// named closures are parsed when their declaration is found
+			return !closures[s]
+		case *ast.DeclStmt:
+			if decl, ok := s.Decl.(*ast.GenDecl); ok {
+				for _, spec := range decl.Specs {
+					switch ts := spec.(type) {
+					case *ast.TypeSpec:
+						p.parseLocalType(ts, scope)
+					case *ast.ValueSpec:
+						for i, name := range ts.Names {
+							var value ast.Expr
+							if i < len(ts.Values) && len(ts.Names) == len(ts.Values) {
+								value = ts.Values[i]
+							}
+							p.parseLocalValue(name, ts.Type, value, scope, closures)
+						}
+					}
+				}
+			}
+		case *ast.AssignStmt:
+			if s.Tok != token.DEFINE {
+				break
+			}
+			for i, lhs := range s.Lhs {
+				name, ok := lhs.(*ast.Ident)
+				if !ok || !declares(name, s) {
+					continue
+				}
+				var value ast.Expr
+				if len(s.Lhs) == len(s.Rhs) {
+					value = s.Rhs[i]
+				}
+				p.parseLocalValue(name, nil, value, scope, closures)
+			}
+		case *ast.RangeStmt:
+			if s.Tok != token.DEFINE {
+				break
+			}
+			for _, e := range []ast.Expr{s.Key, s.Value} {
+				if name, ok := e.(*ast.Ident); ok {
+					p.parseLocalValue(name, nil, nil, scope, closures)
+				}
+			}
+		case *ast.LabeledStmt:
+			tag := p.createTag(s.Label.Name, s.Label.Pos(), token.NoPos, Label)
+			tag.Fields[Scope] = scope
+			tag.Fields[Roles] = RoleDef
+			p.tags = append(p.tags, tag)
+		}
+		return true
+	})
+}
+
+// parseLocalType creates a tag for type declaration ts inside a function body,
+// declared in scope.
+func (p *tagParser) parseLocalType(ts *ast.TypeSpec, scope string) {
+	tag := p.createTag(ts.Name.Name, ts.Pos(), ts.End(), Type)
+	switch ts.Type.(type) {
+	case *ast.StructType:
+		tag.Fields[TypeField] = "struct"
+	case *ast.InterfaceType:
+		tag.Fields[TypeField] = "interface"
+		tag.Type = Interface
+	default:
+		tag.Fields[TypeField] = getType(ts.Type, true)
+	}
+	tag.Fields[Scope] = scope
+	p.tags = append(p.tags, tag)
+}
+
+// parseLocalValue creates a tag for the constant or variable name declared in
+// scope, with type typ and initial value value, both of which may be nil. If
+// value is a function literal, a function tag is created instead and the
+// closure is added to closures, so that its body is not parsed again by the
+// caller.
+func (p *tagParser) parseLocalValue(name *ast.Ident, typ, value ast.Expr, scope string, closures map[*ast.FuncLit]bool) {
+	if name.Name == "_" {
+		return
+	}
+
+	if lit, ok := value.(*ast.FuncLit); ok {
+		tag := p.createTag(name.Name, name.Pos(), lit.End(), Function)
+		tag.Fields[Signature] = fmt.Sprintf("(%s)", getTypes(lit.Type.Params, true))
+		tag.Fields[TypeField] = getTypes(lit.Type.Results, false)
+		tag.Fields[Scope] = scope
+		p.tags = append(p.tags, tag)
+
+		closures[lit] = true
+		closure := scopeOf(Function, p.qualify(scopeName(tag), name.Name))
+		p.parseParams(lit.Type, closure)
+		p.parseBody(lit.Body, closure)
+		return
+	}
+
+	tag := p.createTag(name.Name, name.Pos(), token.NoPos, Variable)
+	if name.Obj != nil && name.Obj.Kind == ast.Con {
+		tag.Type = Constant
+	}
+	if typ != nil {
+		tag.Fields[TypeField] = getType(typ, true)
+	}
+	tag.Fields[Scope] = scope
+	p.tags = append(p.tags, tag)
+}
+
+// declares reports whether identifier name is declared by short variable
+// declaration stmt, rather than being redeclared by it.
+func declares(name *ast.Ident, stmt *ast.AssignStmt) bool {
+	return name.Obj == nil || name.Obj.Decl == stmt
+}
@@ -0,0 +1,130 @@
+package tags
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+// memberType returns the string representation of type expr for the type
+// field of a tag. Anonymous struct and interface types, whose members get
+// tags of their own, are shortened to struct{...} and interface{...}.
+func memberType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StructType:
+		if t.Fields != nil && len(t.Fields.List) > 0 {
+			return "struct{...}"
+		}
+	case *ast.InterfaceType:
+		if t.Methods != nil && len(t.Methods.List) > 0 {
+			return "interface{...}"
+		}
+	}
+	return getType(expr, true)
+}
+
+// parseNestedMembers creates a tag for each field or method of the anonymous
+// struct and interface types in expr. The members are declared in the tag with
+// type kind and name chain, which is the chain of names leading to expr joined
+// by the separator in the options of p, e.g. Config.Server for the type of
+// field Server of type Config. The members of nested anonymous types, of the
+// element types of pointer, slice, array, map and channel types and of the
+// parameter and result types of function types are tagged recursively. The
+// members in the type of parameter or result opts of function Handle are
+// declared in parameter Handle.opts, those of unnamed and blank parameters and
+// results in parameter Handle. The fields and methods of a declared struct or
+// interface type, with kind Type or Interface, also get a ctype or ntype field
+// naming the type.
+func (p *tagParser) parseNestedMembers(chain string, kind TagType, expr ast.Expr) {
+	switch t := expr.(type) {
+	case *ast.ParenExpr:
+		p.parseNestedMembers(chain, kind, t.X)
+	case *ast.StarExpr:
+		p.parseNestedMembers(chain, kind, t.X)
+	case *ast.Ellipsis:
+		p.parseNestedMembers(chain, kind, t.Elt)
+	case *ast.ArrayType:
+		p.parseNestedMembers(chain, kind, t.Elt)
+	case *ast.ChanType:
+		p.parseNestedMembers(chain, kind, t.Value)
+	case *ast.MapType:
+		p.parseNestedMembers(chain, kind, t.Key)
+		p.parseNestedMembers(chain, kind, t.Value)
+	case *ast.FuncType:
+		for _, list := range []*ast.FieldList{t.Params, t.Results} {
+			if list == nil {
+				continue
+			}
+			for _, f := range list.List {
+				if len(f.Names) == 0 {
+					p.parseNestedMembers(chain, Parameter, f.Type)
+				}
+				for _, n := range f.Names {
+					if n.Name == "_" {
+						p.parseNestedMembers(chain, Parameter, f.Type)
+					} else {
+						p.parseNestedMembers(p.qualify(chain, n.Name), Parameter, f.Type)
+					}
+				}
+			}
+		}
+	case *ast.StructType:
+		for _, f := range t.Fields.List {
+			if len(f.Names) == 0 {
+				tag := p.createTag(getType(f.Type, true), f.Pos(), token.NoPos, Embedded)
+				setDoc(&tag, fieldDoc(f))
+				tag.Fields[Access] = getAccess(tag.Name)
+				setMemberScope(tag, kind, chain)
+				tag.Fields[TypeField] = getType(f.Type, true)
+				p.parseStructTag(&tag, f.Pos(), chain, f)
+				p.tags = append(p.tags, tag)
+				continue
+			}
+			for _, n := range f.Names {
+				tag := p.createTag(n.Name, n.Pos(), token.NoPos, Field)
+				setDoc(&tag, fieldDoc(f))
+				tag.Fields[Access] = getAccess(tag.Name)
+				setMemberScope(tag, kind, chain)
+				tag.Fields[TypeField] = memberType(f.Type)
+				p.parseStructTag(&tag, n.Pos(), chain, f)
+				p.tags = append(p.tags, tag)
+
+				p.parseNestedMembers(p.qualify(chain, n.Name), Field, f.Type)
+			}
+		}
+	case *ast.InterfaceType:
+		for _, f := range t.Methods.List {
+			var tag Tag
+			if len(f.Names) > 0 {
+				tag = p.createTag(f.Names[0].Name, f.Names[0].Pos(), f.End(), Method)
+			} else {
+				tag = p.createTag(getType(f.Type, true), f.Pos(), token.NoPos, Embedded)
+			}
+			setDoc(&tag, fieldDoc(f))
+			tag.Fields[Access] = getAccess(tag.Name)
+			if ft, ok := f.Type.(*ast.FuncType); ok {
+				tag.Fields[Signature] = fmt.Sprintf("(%s)", getTypes(ft.Params, true))
+				tag.Fields[TypeField] = getTypes(ft.Results, false)
+			}
+			setMemberScope(tag, kind, chain)
+			p.tags = append(p.tags, tag)
+
+			if len(f.Names) > 0 {
+				p.parseNestedMembers(p.qualify(chain, tag.Name), Method, f.Type)
+			}
+		}
+	}
+}
+
+// setMemberScope sets the scope field of tag, a member of the type declared in
+// the tag with type kind and name chain. Members of a declared struct type get
+// a ctype field and members of a declared interface type an ntype field.
+func setMemberScope(tag Tag, kind TagType, chain string) {
+	tag.Fields[Scope] = scopeOf(kind, chain)
+	switch kind {
+	case Type:
+		tag.Fields[ReceiverType] = chain
+	case Interface:
+		tag.Fields[InterfaceType] = chain
+	}
+}
@@ -0,0 +1,55 @@
+package tags
+
+import (
+	"sync"
+)
+
+// ParseGroups parses each group of files in groups using ParsePackage, running
+// at most jobs parsers concurrently. The tags are returned in the order of
+// groups regardless of the order in which they were parsed, followed by the
+// errors of all files that could not be parsed. With
+// opts.TypeCheck, the packages imported by the groups are type checked only
+// once.
+func ParseGroups(groups [][]string, jobs int, opts Options) ([]Tag, []error) {
+	if jobs < 1 {
+		jobs = 1
+	}
+	if opts.TypeCheck && opts.checker == nil {
+		// share the imported packages between all groups
+		opts.checker = newTypeChecker()
+	}
+
+	results := make([][]Tag, len(groups))
+	errs := make([]error, len(groups))
+
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range work {
+				results[idx], errs[idx] = ParsePackage(groups[idx], opts)
+			}
+		}()
+	}
+
+	for idx := range groups {
+		work <- idx
+	}
+	close(work)
+	wg.Wait()
+
+	tags := []Tag{}
+	var parseErrs []error
+	for idx := range groups {
+		tags = append(tags, results[idx]...)
+		if joined, ok := errs[idx].(interface{ Unwrap() []error }); ok {
+			// one error for each file of the group
+			parseErrs = append(parseErrs, joined.Unwrap()...)
+		} else if errs[idx] != nil {
+			parseErrs = append(parseErrs, errs[idx])
+		}
+	}
+	return tags, parseErrs
+}
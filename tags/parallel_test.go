@@ -0,0 +1,108 @@
+package tags
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParseGroups(t *testing.T) {
+	groups := [][]string{
+		{"testdata/const.go"},
+		{"testdata/missing.go"},
+		{"testdata/func.go"},
+		{"testdata/package/server.go", "testdata/package/server_ctor.go"},
+		{"testdata/struct.go"},
+	}
+
+	serial, serialErrs := ParseGroups(groups, 1, Options{})
+	for _, jobs := range []int{0, 2, 8} {
+		tags, errs := ParseGroups(groups, jobs, Options{})
+		if len(errs) != 1 || len(serialErrs) != 1 {
+			t.Fatalf("[jobs=%d] len(errs) == %d, want 1", jobs, len(errs))
+		}
+		if len(tags) != len(serial) {
+			t.Fatalf("[jobs=%d] len(tags) == %d, want %d", jobs, len(tags), len(serial))
+		}
+		for i := range tags {
+			if tags[i].String() != serial[i].String() {
+				t.Errorf("[jobs=%d] tag(%d)\n  is:%s\nwant:%s", jobs, i, tags[i].String(), serial[i].String())
+			}
+		}
+	}
+}
+
+func TestParseGroupsErrors(t *testing.T) {
+	groups := [][]string{
+		{"testdata/package/server.go", "testdata/package/missing.go", "testdata/package/other.go"},
+		{"testdata/missing.go"},
+	}
+
+	_, errs := ParseGroups(groups, 2, Options{})
+	if len(errs) != 3 {
+		t.Fatalf("len(errs) == %d, want 3: %v", len(errs), errs)
+	}
+	for i, name := range []string{"testdata/package/missing.go", "testdata/package/other.go", "testdata/missing.go"} {
+		if !strings.Contains(errs[i].Error(), name) {
+			t.Errorf("errs[%d] = %s, want an error for %s", i, errs[i], name)
+		}
+	}
+}
+
+func benchmarkParseGroups(b *testing.B, jobs int) {
+	files, err := filepath.Glob("testdata/*.go")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var groups [][]string
+	for i := 0; i < 100; i++ {
+		for _, file := range files {
+			groups = append(groups, []string{file})
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ParseGroups(groups, jobs, Options{})
+	}
+}
+
+func BenchmarkParseSerial(b *testing.B) {
+	benchmarkParseGroups(b, 1)
+}
+
+func BenchmarkParseParallel(b *testing.B) {
+	benchmarkParseGroups(b, runtime.GOMAXPROCS(0))
+}
+
+func TestParseGroupsTypeCheck(t *testing.T) {
+	groups := [][]string{
+		{"testdata/implements/shape.go"},
+		{"testdata/package/server.go", "testdata/package/server_ctor.go"},
+	}
+
+	opts := Options{TypeCheck: true}
+	var want []Tag
+	for _, group := range groups {
+		tags, err := ParsePackage(group, opts)
+		if err != nil {
+			t.Fatalf("unexpected error from ParsePackage: %s", err)
+		}
+		want = append(want, tags...)
+	}
+
+	tags, errs := ParseGroups(groups, 2, opts)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors from ParseGroups: %v", errs)
+	}
+	if len(tags) != len(want) {
+		t.Fatalf("len(tags) == %d, want %d", len(tags), len(want))
+	}
+	for i := range tags {
+		if tags[i].String() != want[i].String() {
+			t.Errorf("tag(%d)\n  is:%s\nwant:%s", i, tags[i].String(), want[i].String())
+		}
+	}
+}
@@ -0,0 +1,492 @@
+package tags
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Options configures how tags are generated.
+type Options struct {
+	// Relative makes the file names in the tags relative to BasePath.
+	Relative bool
+
+	// BasePath is the directory the file names in the tags are relative to,
+	// usually the directory containing the tags file.
+	BasePath string
+
+	// Extra selects the additional tags with qualified names, as returned by
+	// ParseExtras.
+	Extra FieldSet
+
+	// Separator separates the parts of qualified names. If it is empty, "."
+	// is used.
+	Separator string
+
+	// TypeCheck type checks the parsed files to add the interfaces each type
+	// implements and tags for methods promoted from embedded fields. The
+	// files should all belong to the same package.
+	TypeCheck bool
+
+	// checker imports the packages used by the type checked files. It is
+	// shared by the parsers of a run so that imported packages are only type
+	// checked once.
+	checker *typeChecker
+}
+
+// FileName returns the name of file filename as it should appear in a tag. If
+// the relative name of filename cannot be determined, filename is returned
+// unchanged.
+func (o Options) FileName(filename string) string {
+	if !o.Relative {
+		return filename
+	}
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return filename
+	}
+	rel, err := filepath.Rel(o.BasePath, abs)
+	if err != nil {
+		return filename
+	}
+	return rel
+}
+
+// tagParser contains the data needed while parsing.
+type tagParser struct {
+	fset    *token.FileSet
+	tags    []Tag                 // list of created tags
+	types   []string              // all types we encounter, used to determine the constructors
+	sources map[string][]byte     // source of each parsed file
+	consts  map[string]*constSpec // declarations of the top-level constants
+	typedef map[string]ast.Expr   // type expressions of the top-level type declarations
+	opts    Options
+}
+
+// newTagParser returns a parser that creates tags according to opts.
+func newTagParser(opts Options) *tagParser {
+	if opts.Separator == "" {
+		opts.Separator = "."
+	}
+	fset := token.NewFileSet()
+	if opts.TypeCheck {
+		if opts.checker == nil {
+			opts.checker = newTypeChecker()
+		}
+		// positions in imported packages must be in the same file set
+		fset = opts.checker.fset
+	}
+	return &tagParser{
+		fset:    fset,
+		tags:    []Tag{},
+		types:   make([]string, 0),
+		sources: make(map[string][]byte),
+		consts:  make(map[string]*constSpec),
+		typedef: make(map[string]ast.Expr),
+		opts:    opts,
+	}
+}
+
+// Parse parses the source in filename and returns a list of tags.
+func Parse(filename string, opts Options) ([]Tag, error) {
+	tags, err := ParsePackage([]string{filename}, opts)
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// ParseSource parses src, the source of filename, and returns a list of tags.
+// The file is not read, filename is only used in the tags.
+func ParseSource(filename string, src []byte, opts Options) ([]Tag, error) {
+	p := newTagParser(opts)
+	f, err := p.parseFile(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	return p.parse([]*ast.File{f}), nil
+}
+
+// ParseReader parses the source of filename read from r and returns a list of
+// tags. The file is not read, filename is only used in the tags.
+func ParseReader(filename string, r io.Reader, opts Options) ([]Tag, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSource(filename, src, opts)
+}
+
+// ParsePackage parses the source in filenames, which should all belong to the
+// same package, and returns a list of tags. The files share a single list of
+// known types, so a function is recognized as a constructor of a type declared
+// in another file of the package. Files that cannot be parsed are skipped, the
+// errors of all of those files are returned, joined with errors.Join, together
+// with the tags of all other files.
+func ParsePackage(filenames []string, opts Options) ([]Tag, error) {
+	p := newTagParser(opts)
+
+	var files []*ast.File
+	var errs []error
+	for _, filename := range filenames {
+		f, err := p.parseFile(filename, nil)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		files = append(files, f)
+	}
+
+	return p.parse(files), errors.Join(errs...)
+}
+
+// parse creates the tags of files and returns them.
+func (p *tagParser) parse(files []*ast.File) []Tag {
+	for _, f := range files {
+		// package
+		p.parsePackage(f)
+
+		// imports
+		p.parseImports(f)
+	}
+
+	// declarations
+	p.parseDeclarations(files)
+
+	// directives
+	for _, f := range files {
+		p.parseDirectives(f)
+	}
+
+	// type information
+	p.checkTypes(files)
+
+	// references
+	p.parseReferences(files)
+
+	// qualified names
+	p.qualifyTags(files)
+
+	return p.tags
+}
+
+// GroupPackages groups filenames by package, files belong to the same package
+// if they are in the same directory and have the same package clause. A file
+// whose package clause cannot be parsed is put in a group of its own.
+func GroupPackages(filenames []string) [][]string {
+	fset := token.NewFileSet()
+
+	var groups [][]string
+	index := make(map[string]int)
+	for _, filename := range filenames {
+		f, err := parser.ParseFile(fset, filename, nil, parser.PackageClauseOnly)
+		if err != nil {
+			groups = append(groups, []string{filename})
+			continue
+		}
+
+		key := filepath.Join(filepath.Dir(filename), f.Name.Name)
+		if i, ok := index[key]; ok {
+			groups[i] = append(groups[i], filename)
+		} else {
+			index[key] = len(groups)
+			groups = append(groups, []string{filename})
+		}
+	}
+	return groups
+}
+
+// parseFile parses the source of filename. If src is nil, the source is read
+// from filename.
+func (p *tagParser) parseFile(filename string, src []byte) (*ast.File, error) {
+	if src == nil {
+		var err error
+		if src, err = os.ReadFile(filename); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := parser.ParseFile(p.fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	p.sources[filename] = src
+	return f, nil
+}
+
+// parsePackage creates a package tag.
+func (p *tagParser) parsePackage(f *ast.File) string {
+	tag := p.createTag(f.Name.Name, f.Name.Pos(), token.NoPos, Package)
+	setDoc(&tag, f.Doc)
+	p.tags = append(p.tags, tag)
+	return f.Name.Name
+}
+
+// parseImports creates an import tag for each import.
+func (p *tagParser) parseImports(f *ast.File) {
+	for _, im := range f.Imports {
+		name := strings.Trim(im.Path.Value, "\"")
+		p.tags = append(p.tags, p.createTag(name, im.Path.Pos(), token.NoPos, Import))
+	}
+}
+
+// parseDeclarations creates a tag for each function, type or value declaration
+// in files.
+func (p *tagParser) parseDeclarations(files []*ast.File) {
+	// the values of constants may depend on constants declared later
+	p.collectConsts(files)
+
+	// first parse the type and value declarations, so that we have a list of all
+	// known types before parsing the functions.
+	for _, f := range files {
+		for _, d := range f.Decls {
+			if decl, ok := d.(*ast.GenDecl); ok {
+				for _, s := range decl.Specs {
+					switch ts := s.(type) {
+					case *ast.TypeSpec:
+						p.parseTypeDeclaration(ts, specDoc(decl, ts.Doc))
+					case *ast.ValueSpec:
+						p.parseValueDeclaration(ts, specDoc(decl, ts.Doc))
+					}
+				}
+			}
+		}
+	}
+
+	// now parse all the functions
+	for _, f := range files {
+		for _, d := range f.Decls {
+			if decl, ok := d.(*ast.FuncDecl); ok {
+				p.parseFunction(decl)
+			}
+		}
+	}
+}
+
+// parseFunction creates a tag for function declaration f.
+func (p *tagParser) parseFunction(f *ast.FuncDecl) {
+	tag := p.createTag(f.Name.Name, f.Name.Pos(), f.End(), Function)
+	setDoc(&tag, f.Doc)
+
+	tag.Fields[Access] = getAccess(tag.Name)
+	tag.Fields[Signature] = fmt.Sprintf("(%s)", getTypes(f.Type.Params, true))
+	tag.Fields[TypeField] = getTypes(f.Type.Results, false)
+	if f.Type.TypeParams != nil {
+		tag.Fields[TypeParams] = fmt.Sprintf("[%s]", getTypes(f.Type.TypeParams, true))
+	}
+
+	if f.Recv != nil && len(f.Recv.List) > 0 {
+		// this function has a receiver, set the type to Method
+		tag.Fields[ReceiverType] = getBaseType(f.Recv.List[0].Type)
+		tag.Fields[Scope] = scopeOf(Type, tag.Fields[ReceiverType])
+		tag.Type = Method
+	} else if name, ok := p.belongsToReceiver(f.Type.Results); ok {
+		// this function does not have a receiver, but it belongs to one based
+		// on its return values; its type will be Function instead of Method.
+		tag.Fields[ReceiverType] = name
+		tag.Type = Function
+	}
+
+	p.tags = append(p.tags, tag)
+
+	if tag.Type == Function {
+		p.parseTypeParams(f.Type.TypeParams, tag)
+	}
+	if f.Recv != nil && len(f.Recv.List) > 0 {
+		p.parseNestedMembers(p.qualify(tag.Fields[ReceiverType], tag.Name), Method, f.Type)
+	} else {
+		p.parseNestedMembers(tag.Name, Function, f.Type)
+	}
+	p.parseLocals(f, tag)
+}
+
+// parseTypeDeclaration creates a tag for type declaration ts with doc comment
+// doc and for each field in case of a struct, or each method in case of an
+// interface.
+func (p *tagParser) parseTypeDeclaration(ts *ast.TypeSpec, doc *ast.CommentGroup) {
+	tag := p.createTag(ts.Name.Name, ts.Pos(), ts.End(), Type)
+	setDoc(&tag, doc)
+
+	tag.Fields[Access] = getAccess(tag.Name)
+	if ts.TypeParams != nil {
+		tag.Fields[TypeParams] = fmt.Sprintf("[%s]", getTypes(ts.TypeParams, true))
+	}
+
+	switch s := ts.Type.(type) {
+	case *ast.StructType:
+		tag.Fields[TypeField] = "struct"
+		p.parseNestedMembers(tag.Name, Type, s)
+		p.parseTypeParams(ts.TypeParams, tag)
+		p.types = append(p.types, tag.Name)
+	case *ast.InterfaceType:
+		tag.Fields[TypeField] = "interface"
+		tag.Type = Interface
+		p.parseNestedMembers(tag.Name, Interface, s)
+		p.parseTypeParams(ts.TypeParams, tag)
+	default:
+		tag.Fields[TypeField] = getType(ts.Type, true)
+		p.parseTypeParams(ts.TypeParams, tag)
+		p.parseNestedMembers(tag.Name, Type, ts.Type)
+	}
+
+	p.tags = append(p.tags, tag)
+}
+
+// parseValueDeclaration creates a tag for each variable or constant declaration
+// in v with doc comment doc, unless the declaration uses a blank identifier. If
+// the type of a variable is an anonymous struct or interface type, its members
+// are tagged as well.
+func (p *tagParser) parseValueDeclaration(v *ast.ValueSpec, doc *ast.CommentGroup) {
+	for i, d := range v.Names {
+		if d.Name == "_" {
+			continue
+		}
+
+		tag := p.createTag(d.Name, d.Pos(), token.NoPos, Variable)
+		tag.Fields[Access] = getAccess(tag.Name)
+		setDoc(&tag, doc)
+
+		if v.Type != nil {
+			tag.Fields[TypeField] = memberType(v.Type)
+		}
+
+		switch d.Obj.Kind {
+		case ast.Var:
+			tag.Type = Variable
+		case ast.Con:
+			tag.Type = Constant
+			p.parseConstant(tag, v)
+		}
+		p.tags = append(p.tags, tag)
+
+		typ := v.Type
+		if typ == nil {
+			switch lit := valueAt(v, i).(type) {
+			case *ast.CompositeLit:
+				typ = lit.Type
+			case *ast.FuncLit:
+				typ = lit.Type
+			}
+		}
+		p.parseNestedMembers(tag.Name, tag.Type, typ)
+	}
+}
+
+// valueAt returns the value assigned to the i-th name of v, or nil if there is
+// no single value for each name.
+func valueAt(v *ast.ValueSpec, i int) ast.Expr {
+	if len(v.Values) != len(v.Names) {
+		return nil
+	}
+	return v.Values[i]
+}
+
+// parseTypeParams creates a tag for each type parameter in params. The scope
+// of each tag is set to owner, the tag of the type or function the type
+// parameters belong to.
+func (p *tagParser) parseTypeParams(params *ast.FieldList, owner Tag) {
+	if params == nil {
+		return
+	}
+
+	field := ReceiverType
+	switch owner.Type {
+	case Interface:
+		field = InterfaceType
+	case Function:
+		field = FunctionScope
+	}
+
+	for _, f := range params.List {
+		for _, n := range f.Names {
+			tag := p.createTag(n.Name, n.Pos(), token.NoPos, TypeParam)
+			tag.Fields[TypeField] = getType(f.Type, true)
+			tag.Fields[field] = owner.Name
+			tag.Fields[Scope] = scopeOf(owner.Type, owner.Name)
+			p.tags = append(p.tags, tag)
+		}
+	}
+}
+
+// createTag creates a new tag, using pos to find the filename and set the line
+// number. If end is valid, it is used to set the end line of the tag.
+func (p *tagParser) createTag(name string, pos, end token.Pos, tagType TagType) Tag {
+	f := p.opts.FileName(p.fset.File(pos).Name())
+	position := p.fset.Position(pos)
+	tag := NewTag(name, f, position.Line, tagType)
+	tag.Column = position.Column
+	tag.Offset = position.Offset
+	tag.Source = p.sourceLine(position)
+	if end.IsValid() {
+		tag.EndLine = p.fset.Position(end).Line
+	}
+	return tag
+}
+
+// sourceLine returns the text of the line in the current source at position,
+// without the line ending.
+func (p *tagParser) sourceLine(position token.Position) string {
+	start := position.Offset - (position.Column - 1)
+	src := p.sources[position.Filename]
+	if start < 0 || position.Offset > len(src) {
+		return ""
+	}
+
+	line := src[start:]
+	if end := bytes.IndexByte(line, '\n'); end >= 0 {
+		line = line[:end]
+	}
+	return string(bytes.TrimSuffix(line, []byte("\r")))
+}
+
+// belongsToReceiver checks if a function with these return types belongs to
+// a receiver. If it belongs to a receiver, the name of that receiver will be
+// returned with ok set to true. Otherwise ok will be false.
+// Behavior should be similar to how go doc decides when a function belongs to
+// a receiver (gosrc/pkg/go/doc/reader.go).
+func (p *tagParser) belongsToReceiver(types *ast.FieldList) (name string, ok bool) {
+	if types == nil || types.NumFields() == 0 {
+		return "", false
+	}
+
+	// If the first return type has more than 1 result associated with
+	// it, it should not belong to that receiver.
+	// Similar behavior as go doc (go source/.
+	if len(types.List[0].Names) > 1 {
+		return "", false
+	}
+
+	// get name of the first return type
+	t := getBaseType(types.List[0].Type)
+
+	// check if it exists in the current list of known types
+	for _, knownType := range p.types {
+		if t == knownType {
+			return knownType, true
+		}
+	}
+
+	return "", false
+}
+
+// getAccess returns the string "public" if name is considered an exported name, otherwise
+// the string "private" is returned.
+func getAccess(name string) (access string) {
+	if idx := strings.LastIndex(name, "."); idx > -1 && idx < len(name) {
+		name = name[idx+1:]
+	}
+
+	if ast.IsExported(name) {
+		access = "public"
+	} else {
+		access = "private"
+	}
+	return
+}
@@ -0,0 +1,825 @@
+package tags
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var goVersionRegexp = regexp.MustCompile(`^go1(?:\.(\d+))?`)
+
+// This type is used to implement the sort.Interface interface
+// in order to be able to sort an array of Tag
+type TagSlice []Tag
+
+// Return the len of the array
+func (t TagSlice) Len() int {
+	return len(t)
+}
+
+// Compare two elements of a tag array
+func (t TagSlice) Less(i, j int) bool {
+	return t[i].String() < t[j].String()
+}
+
+// Swap two elements of the underlying array
+func (t TagSlice) Swap(i, j int) {
+	t[i], t[j] = t[j], t[i]
+}
+
+// Dump the names of the tags in a TagSlice
+func (t TagSlice) Dump() {
+	for idx, val := range t {
+		fmt.Println(idx, val.Name)
+	}
+}
+
+type F map[TagField]string
+
+var testCases = []struct {
+	filename         string
+	relative         bool
+	basepath         string
+	minversion       int
+	withExtraSymbols bool
+	extra            FieldSet
+	separator        string
+	tags             []Tag
+}{
+	{filename: "testdata/const.go", tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("Constant", 3, "c", F{"access": "public", "type": "string", "value": "\"const\""}),
+		tag("OtherConst", 4, "c", F{"access": "public", "value": "\"const\""}),
+		tag("A", 7, "c", F{"access": "public", "value": "true"}),
+		tag("B", 8, "c", F{"access": "public", "value": "\"c\""}),
+		tag("C", 8, "c", F{"access": "public", "value": "\"d\""}),
+		tag("D", 9, "c", F{"access": "public", "value": "0"}),
+	}},
+	{filename: "testdata/const.go", withExtraSymbols: true, tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("Constant", 3, "c", F{"access": "public", "type": "string", "value": "\"const\""}),
+		tag("OtherConst", 4, "c", F{"access": "public", "value": "\"const\""}),
+		tag("A", 7, "c", F{"access": "public", "value": "true"}),
+		tag("B", 8, "c", F{"access": "public", "value": "\"c\""}),
+		tag("C", 8, "c", F{"access": "public", "value": "\"d\""}),
+		tag("D", 9, "c", F{"access": "public", "value": "0"}),
+		tag("Test.Constant", 3, "c", F{"access": "public", "type": "string", "value": "\"const\""}),
+		tag("Test.OtherConst", 4, "c", F{"access": "public", "value": "\"const\""}),
+		tag("Test.A", 7, "c", F{"access": "public", "value": "true"}),
+		tag("Test.B", 8, "c", F{"access": "public", "value": "\"c\""}),
+		tag("Test.C", 8, "c", F{"access": "public", "value": "\"d\""}),
+		tag("Test.D", 9, "c", F{"access": "public", "value": "0"}),
+	}},
+	{filename: "testdata/iota.go", tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("time", 4, "i", F{}),
+		tag("unsafe", 5, "i", F{}),
+		tag("Status", 8, "t", F{"access": "public", "type": "int"}),
+		tag("StatusOK", 11, "c", F{"access": "public", "type": "Status", "value": "0"}),
+		tag("StatusFailed", 12, "c", F{"access": "public", "type": "Status", "value": "1"}),
+		tag("StatusUnknown", 14, "c", F{"access": "public", "type": "Status", "value": "3"}),
+		tag("KB", 18, "c", F{"access": "public", "value": "1024"}),
+		tag("MB", 19, "c", F{"access": "public", "value": "1048576"}),
+		tag("GB", 20, "c", F{"access": "public", "value": "1073741824"}),
+		tag("MaxUint8", 24, "c", F{"access": "public", "value": "255"}),
+		tag("MinInt", 25, "c", F{"access": "public", "value": "-1"}),
+		tag("Half", 26, "c", F{"access": "public", "value": "3"}),
+		tag("Ratio", 27, "c", F{"access": "public", "value": "3.5"}),
+		tag("Name", 28, "c", F{"access": "public", "value": "\"gotags-1\""}),
+		tag("NameLen", 29, "c", F{"access": "public", "value": "8"}),
+		tag("Enabled", 30, "c", F{"access": "public", "value": "true"}),
+		tag("Timeout", 31, "c", F{"access": "public"}),
+		tag("Version", 32, "c", F{"access": "public", "value": "\"1\""}),
+		tag("Undefined", 33, "c", F{"access": "public"}),
+		tag("Letter", 34, "c", F{"access": "public", "value": "\"A\""}),
+		tag("Size", 35, "c", F{"access": "public"}),
+		tag("Code", 36, "c", F{"access": "public", "value": "7"}),
+		tag("Banner", 37, "c", F{"access": "public", "value": "\"a string constant that is longer than the seventy-two characters shown by String\""}),
+	}},
+	{filename: "testdata/directives.go", tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("embed", 4, "i", F{}),
+		tag("unsafe", 5, "i", F{}),
+		tag("index", 13, "v", F{"access": "private", "doc": "index is the main page.", "type": "string"}),
+		tag("files", 18, "v", F{"access": "private", "type": "string"}),
+		tag("nanotime", 22, "f", F{"access": "private", "signature": "()", "type": "int64"}),
+		tag("Add", 25, "f", F{"access": "public", "signature": "(a, b int)", "type": "int"}),
+		tag("stringer", 8, "D", F{"directive": "go:generate", "target": "stringer -type=Color"}),
+		tag("index", 12, "D", F{"directive": "go:embed", "target": "static/index.html"}),
+		tag("files", 16, "D", F{"directive": "go:embed", "target": "templates/*.tmpl"}),
+		tag("files", 17, "D", F{"directive": "go:embed", "target": "static"}),
+		tag("nanotime", 21, "D", F{"directive": "go:linkname", "target": "runtime.nanotime"}),
+		tag("Add", 24, "D", F{"directive": "export", "target": "Add"}),
+	}},
+	{filename: "testdata/labels.go", extra: FieldSet{LocalTags: true}, tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("Retry", 3, "f", F{"access": "public", "signature": "(n int)"}),
+		tag("n", 3, "z", F{"scope": "function:Retry", "type": "int"}),
+		tag("i", 4, "v", F{"scope": "function:Retry"}),
+		tag("again", 5, "l", F{"roles": "def", "scope": "function:Retry"}),
+		tag("rows", 10, "l", F{"roles": "def", "scope": "function:Retry"}),
+	}},
+	{filename: "testdata/labels.go", extra: FieldSet{ReferenceTags: true}, tags: []Tag{
+		tag("Test", 1, "p", F{"roles": "def"}),
+		tag("Retry", 3, "f", F{"access": "public", "roles": "def", "signature": "(n int)"}),
+		tag("again", 8, "l", F{"roles": "goto"}),
+		tag("rows", 14, "l", F{"roles": "continue"}),
+		tag("rows", 16, "l", F{"roles": "break"}),
+	}},
+	{filename: "testdata/local.go", tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("Function", 3, "f", F{"access": "public", "signature": "(a int, b string)", "type": "int"}),
+		tag("Method", 29, "m", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "signature": "()"}),
+		tag("Struct", 34, "t", F{"access": "public", "type": "struct"}),
+	}},
+	{filename: "testdata/local.go", withExtraSymbols: true, extra: FieldSet{LocalTags: true}, tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("Function", 3, "f", F{"access": "public", "signature": "(a int, b string)", "type": "int"}),
+		tag("a", 3, "z", F{"scope": "function:Function", "type": "int"}),
+		tag("b", 3, "z", F{"scope": "function:Function", "type": "string"}),
+		tag("n", 3, "z", F{"scope": "function:Function", "type": "int"}),
+		tag("point", 4, "t", F{"scope": "function:Function", "type": "struct"}),
+		tag("limit", 5, "c", F{"scope": "function:Function"}),
+		tag("total", 6, "v", F{"scope": "function:Function", "type": "int"}),
+		tag("count", 7, "v", F{"scope": "function:Function"}),
+		tag("i", 9, "v", F{"scope": "function:Function"}),
+		tag("v", 9, "v", F{"scope": "function:Function"}),
+		tag("add", 13, "f", F{"scope": "function:Function", "signature": "(x int)", "type": "int"}),
+		tag("x", 13, "z", F{"scope": "function:Function.add", "type": "int"}),
+		tag("sum", 14, "v", F{"scope": "function:Function.add"}),
+		tag("outer", 18, "l", F{"roles": "def", "scope": "function:Function"}),
+		tag("count", 20, "v", F{"scope": "function:Function"}),
+		tag("err", 20, "v", F{"scope": "function:Function"}),
+		tag("Method", 29, "m", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "signature": "()"}),
+		tag("local", 30, "v", F{"scope": "method:Struct.Method"}),
+		tag("Struct", 34, "t", F{"access": "public", "type": "struct"}),
+		tag("Test.Function", 3, "f", F{"access": "public", "signature": "(a int, b string)", "type": "int"}),
+		tag("Test.Method", 29, "m", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "signature": "()"}),
+		tag("Struct.Method", 29, "m", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "signature": "()"}),
+		tag("Test.Struct.Method", 29, "m", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "signature": "()"}),
+		tag("Test.Struct", 34, "t", F{"access": "public", "type": "struct"}),
+	}},
+	{filename: "testdata/local.go", extra: FieldSet{LocalTags: true}, separator: "::", tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("Function", 3, "f", F{"access": "public", "signature": "(a int, b string)", "type": "int"}),
+		tag("a", 3, "z", F{"scope": "function:Function", "type": "int"}),
+		tag("b", 3, "z", F{"scope": "function:Function", "type": "string"}),
+		tag("n", 3, "z", F{"scope": "function:Function", "type": "int"}),
+		tag("point", 4, "t", F{"scope": "function:Function", "type": "struct"}),
+		tag("limit", 5, "c", F{"scope": "function:Function"}),
+		tag("total", 6, "v", F{"scope": "function:Function", "type": "int"}),
+		tag("count", 7, "v", F{"scope": "function:Function"}),
+		tag("i", 9, "v", F{"scope": "function:Function"}),
+		tag("v", 9, "v", F{"scope": "function:Function"}),
+		tag("add", 13, "f", F{"scope": "function:Function", "signature": "(x int)", "type": "int"}),
+		tag("x", 13, "z", F{"scope": "function:Function::add", "type": "int"}),
+		tag("sum", 14, "v", F{"scope": "function:Function::add"}),
+		tag("outer", 18, "l", F{"roles": "def", "scope": "function:Function"}),
+		tag("count", 20, "v", F{"scope": "function:Function"}),
+		tag("err", 20, "v", F{"scope": "function:Function"}),
+		tag("Method", 29, "m", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "signature": "()"}),
+		tag("local", 30, "v", F{"scope": "method:Struct::Method"}),
+		tag("Struct", 34, "t", F{"access": "public", "type": "struct"}),
+	}},
+	{filename: "testdata/func.go", tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("Function1", 3, "f", F{"access": "public", "signature": "()", "type": "string"}),
+		tag("function2", 6, "f", F{"access": "private", "signature": "(p1, p2 int, p3 *string)"}),
+		tag("function3", 9, "f", F{"access": "private", "signature": "()", "type": "bool"}),
+		tag("function4", 12, "f", F{"access": "private", "signature": "(p interface{})", "type": "interface{}"}),
+		tag("function5", 15, "f", F{"access": "private", "signature": "()", "type": "string, string, error"}),
+		tag("function6", 18, "f", F{"access": "private", "signature": "(v ...interface{})"}),
+		tag("function7", 21, "f", F{"access": "private", "signature": "(s ...string)"}),
+	}},
+	{filename: "testdata/func.go", withExtraSymbols: true, tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("Test.Function1", 3, "f", F{"access": "public", "signature": "()", "type": "string"}),
+		tag("Test.function2", 6, "f", F{"access": "private", "signature": "(p1, p2 int, p3 *string)"}),
+		tag("Test.function3", 9, "f", F{"access": "private", "signature": "()", "type": "bool"}),
+		tag("Test.function4", 12, "f", F{"access": "private", "signature": "(p interface{})", "type": "interface{}"}),
+		tag("Test.function5", 15, "f", F{"access": "private", "signature": "()", "type": "string, string, error"}),
+		tag("Test.function6", 18, "f", F{"access": "private", "signature": "(v ...interface{})"}),
+		tag("Test.function7", 21, "f", F{"access": "private", "signature": "(s ...string)"}),
+		tag("Function1", 3, "f", F{"access": "public", "signature": "()", "type": "string"}),
+		tag("function2", 6, "f", F{"access": "private", "signature": "(p1, p2 int, p3 *string)"}),
+		tag("function3", 9, "f", F{"access": "private", "signature": "()", "type": "bool"}),
+		tag("function4", 12, "f", F{"access": "private", "signature": "(p interface{})", "type": "interface{}"}),
+		tag("function5", 15, "f", F{"access": "private", "signature": "()", "type": "string, string, error"}),
+		tag("function6", 18, "f", F{"access": "private", "signature": "(v ...interface{})"}),
+		tag("function7", 21, "f", F{"access": "private", "signature": "(s ...string)"}),
+	}},
+	{filename: "testdata/generics.go", minversion: 18, tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("items", 4, "w", F{"access": "private", "ctype": "List", "scope": "type:List", "type": "[]T"}),
+		tag("T", 3, "Z", F{"ctype": "List", "scope": "type:List", "type": "any"}),
+		tag("List", 3, "t", F{"access": "public", "type": "struct", "typeparams": "[T any]"}),
+		tag("T", 7, "Z", F{"ctype": "Set", "scope": "type:Set", "type": "comparable"}),
+		tag("Set", 7, "t", F{"access": "public", "type": "map[T]bool", "typeparams": "[T comparable]"}),
+		tag("Get", 10, "m", F{"access": "public", "ntype": "Container", "scope": "interface:Container", "signature": "(K)", "type": "V"}),
+		tag("K", 9, "Z", F{"ntype": "Container", "scope": "interface:Container", "type": "comparable"}),
+		tag("V", 9, "Z", F{"ntype": "Container", "scope": "interface:Container", "type": "any"}),
+		tag("Container", 9, "n", F{"access": "public", "type": "interface", "typeparams": "[K comparable, V any]"}),
+		tag("IntList", 13, "t", F{"access": "public", "type": "List[int]"}),
+		tag("NewList", 15, "f", F{"access": "public", "ctype": "List", "signature": "()", "type": "*List[T]", "typeparams": "[T any]"}),
+		tag("T", 15, "Z", F{"function": "NewList", "scope": "function:NewList", "type": "any"}),
+		tag("Push", 18, "m", F{"access": "public", "ctype": "List", "scope": "type:List", "signature": "(v T)"}),
+		tag("Map", 21, "f", F{"access": "public", "signature": "(m map[K]V)", "type": "Set[K]", "typeparams": "[K comparable, V any]"}),
+		tag("K", 21, "Z", F{"function": "Map", "scope": "function:Map", "type": "comparable"}),
+		tag("V", 21, "Z", F{"function": "Map", "scope": "function:Map", "type": "any"}),
+	}},
+	{filename: "testdata/generics.go", minversion: 18, withExtraSymbols: true, tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("items", 4, "w", F{"access": "private", "ctype": "List", "scope": "type:List", "type": "[]T"}),
+		tag("T", 3, "Z", F{"ctype": "List", "scope": "type:List", "type": "any"}),
+		tag("List", 3, "t", F{"access": "public", "type": "struct", "typeparams": "[T any]"}),
+		tag("T", 7, "Z", F{"ctype": "Set", "scope": "type:Set", "type": "comparable"}),
+		tag("Set", 7, "t", F{"access": "public", "type": "map[T]bool", "typeparams": "[T comparable]"}),
+		tag("Get", 10, "m", F{"access": "public", "ntype": "Container", "scope": "interface:Container", "signature": "(K)", "type": "V"}),
+		tag("K", 9, "Z", F{"ntype": "Container", "scope": "interface:Container", "type": "comparable"}),
+		tag("V", 9, "Z", F{"ntype": "Container", "scope": "interface:Container", "type": "any"}),
+		tag("Container", 9, "n", F{"access": "public", "type": "interface", "typeparams": "[K comparable, V any]"}),
+		tag("IntList", 13, "t", F{"access": "public", "type": "List[int]"}),
+		tag("NewList", 15, "f", F{"access": "public", "ctype": "List", "signature": "()", "type": "*List[T]", "typeparams": "[T any]"}),
+		tag("T", 15, "Z", F{"function": "NewList", "scope": "function:NewList", "type": "any"}),
+		tag("Push", 18, "m", F{"access": "public", "ctype": "List", "scope": "type:List", "signature": "(v T)"}),
+		tag("Map", 21, "f", F{"access": "public", "signature": "(m map[K]V)", "type": "Set[K]", "typeparams": "[K comparable, V any]"}),
+		tag("K", 21, "Z", F{"function": "Map", "scope": "function:Map", "type": "comparable"}),
+		tag("V", 21, "Z", F{"function": "Map", "scope": "function:Map", "type": "any"}),
+		tag("Test.List", 3, "t", F{"access": "public", "type": "struct", "typeparams": "[T any]"}),
+		tag("Test.Set", 7, "t", F{"access": "public", "type": "map[T]bool", "typeparams": "[T comparable]"}),
+		tag("Test.Container", 9, "n", F{"access": "public", "type": "interface", "typeparams": "[K comparable, V any]"}),
+		tag("Test.IntList", 13, "t", F{"access": "public", "type": "List[int]"}),
+		tag("Test.NewList", 15, "f", F{"access": "public", "ctype": "List", "signature": "()", "type": "*List[T]", "typeparams": "[T any]"}),
+		tag("Test.Push", 18, "m", F{"access": "public", "ctype": "List", "scope": "type:List", "signature": "(v T)"}),
+		tag("List.Push", 18, "m", F{"access": "public", "ctype": "List", "scope": "type:List", "signature": "(v T)"}),
+		tag("Test.List.Push", 18, "m", F{"access": "public", "ctype": "List", "scope": "type:List", "signature": "(v T)"}),
+		tag("Test.Map", 21, "f", F{"access": "public", "signature": "(m map[K]V)", "type": "Set[K]", "typeparams": "[K comparable, V any]"}),
+		tag("Test.items", 4, "w", F{"access": "private", "ctype": "List", "scope": "type:List", "type": "[]T"}),
+		tag("List.items", 4, "w", F{"access": "private", "ctype": "List", "scope": "type:List", "type": "[]T"}),
+		tag("Test.List.items", 4, "w", F{"access": "private", "ctype": "List", "scope": "type:List", "type": "[]T"}),
+		tag("Test.Get", 10, "m", F{"access": "public", "ntype": "Container", "scope": "interface:Container", "signature": "(K)", "type": "V"}),
+		tag("Container.Get", 10, "m", F{"access": "public", "ntype": "Container", "scope": "interface:Container", "signature": "(K)", "type": "V"}),
+		tag("Test.Container.Get", 10, "m", F{"access": "public", "ntype": "Container", "scope": "interface:Container", "signature": "(K)", "type": "V"}),
+	}},
+	{filename: "testdata/import.go", tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("fmt", 3, "i", F{}),
+		tag("go/ast", 6, "i", F{}),
+		tag("go/parser", 7, "i", F{}),
+	}},
+	{filename: "testdata/import.go", withExtraSymbols: true, tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("fmt", 3, "i", F{}),
+		tag("go/ast", 6, "i", F{}),
+		tag("go/parser", 7, "i", F{}),
+	}},
+	{filename: "testdata/interface.go", tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("InterfaceMethod", 4, "m", F{"access": "public", "signature": "(int)", "ntype": "Interface", "scope": "interface:Interface", "type": "string"}),
+		tag("OtherMethod", 5, "m", F{"access": "public", "signature": "()", "ntype": "Interface", "scope": "interface:Interface"}),
+		tag("io.Reader", 6, "e", F{"access": "public", "ntype": "Interface", "scope": "interface:Interface"}),
+		tag("Interface", 3, "n", F{"access": "public", "type": "interface"}),
+	}},
+	{filename: "testdata/interface.go", withExtraSymbols: true, tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("InterfaceMethod", 4, "m", F{"access": "public", "signature": "(int)", "ntype": "Interface", "scope": "interface:Interface", "type": "string"}),
+		tag("OtherMethod", 5, "m", F{"access": "public", "signature": "()", "ntype": "Interface", "scope": "interface:Interface"}),
+		tag("io.Reader", 6, "e", F{"access": "public", "ntype": "Interface", "scope": "interface:Interface"}),
+		tag("Interface", 3, "n", F{"access": "public", "type": "interface"}),
+		tag("Test.Interface", 3, "n", F{"access": "public", "type": "interface"}),
+		tag("Test.InterfaceMethod", 4, "m", F{"access": "public", "signature": "(int)", "ntype": "Interface", "scope": "interface:Interface", "type": "string"}),
+		tag("Interface.InterfaceMethod", 4, "m", F{"access": "public", "signature": "(int)", "ntype": "Interface", "scope": "interface:Interface", "type": "string"}),
+		tag("Test.Interface.InterfaceMethod", 4, "m", F{"access": "public", "signature": "(int)", "ntype": "Interface", "scope": "interface:Interface", "type": "string"}),
+		tag("Test.OtherMethod", 5, "m", F{"access": "public", "signature": "()", "ntype": "Interface", "scope": "interface:Interface"}),
+		tag("Interface.OtherMethod", 5, "m", F{"access": "public", "signature": "()", "ntype": "Interface", "scope": "interface:Interface"}),
+		tag("Test.Interface.OtherMethod", 5, "m", F{"access": "public", "signature": "()", "ntype": "Interface", "scope": "interface:Interface"}),
+	}},
+	{filename: "testdata/nested.go", tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("Config", 3, "t", F{"access": "public", "type": "struct"}),
+		tag("Name", 4, "w", F{"access": "public", "ctype": "Config", "scope": "type:Config", "type": "string"}),
+		tag("Server", 5, "w", F{"access": "public", "ctype": "Config", "scope": "type:Config", "type": "struct{...}"}),
+		tag("Host", 6, "w", F{"access": "public", "scope": "field:Config.Server", "type": "string"}),
+		tag("Port", 7, "w", F{"access": "public", "scope": "field:Config.Server", "type": "int"}),
+		tag("TLS", 8, "w", F{"access": "public", "scope": "field:Config.Server", "type": "struct{...}"}),
+		tag("Cert", 9, "w", F{"access": "public", "scope": "field:Config.Server.TLS", "type": "string"}),
+		tag("Logger", 12, "w", F{"access": "public", "ctype": "Config", "scope": "type:Config", "type": "interface{...}"}),
+		tag("Log", 13, "m", F{"access": "public", "scope": "field:Config.Logger", "signature": "(msg string)"}),
+		tag("cfg", 17, "v", F{"access": "private", "type": "struct{...}"}),
+		tag("Debug", 18, "w", F{"access": "public", "scope": "variable:cfg", "type": "bool"}),
+		tag("defaults", 21, "v", F{"access": "private"}),
+		tag("Retries", 22, "w", F{"access": "public", "scope": "variable:defaults", "type": "int"}),
+		tag("ID", 26, "w", F{"access": "public", "scope": "parameter:Handler.req", "type": "int"}),
+		tag("Status", 28, "w", F{"access": "public", "scope": "parameter:Handler", "type": "int"}),
+		tag("Handler", 25, "t", F{"access": "public", "type": "func(req struct{ ID int }) []struct{ Status int }"}),
+		tag("callback", 38, "v", F{"access": "private"}),
+		tag("Name", 38, "w", F{"access": "public", "scope": "parameter:callback.event", "type": "string"}),
+		tag("Call", 41, "m", F{"access": "public", "ntype": "Service", "scope": "interface:Service", "signature": "(args struct{ Method string })", "type": "error"}),
+		tag("Method", 41, "w", F{"access": "public", "scope": "parameter:Service.Call.args", "type": "string"}),
+		tag("Service", 40, "n", F{"access": "public", "type": "interface"}),
+		tag("Handle", 31, "f", F{"access": "public", "signature": "(opts *struct{ Verbose bool }, _ map[string]struct{ Count int })"}),
+		tag("Verbose", 32, "w", F{"access": "public", "scope": "parameter:Handle.opts", "type": "bool"}),
+		tag("Count", 33, "w", F{"access": "public", "scope": "parameter:Handle", "type": "int"}),
+		tag("Validate", 36, "m", F{"access": "public", "ctype": "Config", "scope": "type:Config", "signature": "(rules []struct{ Field string })"}),
+		tag("Field", 36, "w", F{"access": "public", "scope": "parameter:Config.Validate.rules", "type": "string"}),
+	}},
+	{filename: "testdata/struct.go", tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("Field1", 4, "w", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "type": "int"}),
+		tag("Field2", 4, "w", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "type": "int"}),
+		tag("field3", 5, "w", F{"access": "private", "ctype": "Struct", "scope": "type:Struct", "type": "string"}),
+		tag("field4", 6, "w", F{"access": "private", "ctype": "Struct", "scope": "type:Struct", "type": "*bool"}),
+		tag("Struct", 3, "t", F{"access": "public", "type": "struct"}),
+		tag("Struct", 20, "e", F{"access": "public", "ctype": "TestEmbed", "scope": "type:TestEmbed", "type": "Struct"}),
+		tag("*io.Writer", 21, "e", F{"access": "public", "ctype": "TestEmbed", "scope": "type:TestEmbed", "type": "*io.Writer"}),
+		tag("TestEmbed", 19, "t", F{"access": "public", "type": "struct"}),
+		tag("Struct2", 27, "t", F{"access": "public", "type": "struct"}),
+		tag("Connection", 36, "t", F{"access": "public", "type": "struct"}),
+		tag("NewStruct", 9, "f", F{"access": "public", "ctype": "Struct", "signature": "()", "type": "*Struct"}),
+		tag("F1", 13, "m", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "signature": "()", "type": "[]bool, [2]*string"}),
+		tag("F2", 16, "m", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "signature": "()", "type": "bool"}),
+		tag("NewTestEmbed", 24, "f", F{"access": "public", "ctype": "TestEmbed", "signature": "()", "type": "TestEmbed"}),
+		tag("NewStruct2", 30, "f", F{"access": "public", "ctype": "Struct2", "signature": "()", "type": "*Struct2, error"}),
+		tag("Dial", 33, "f", F{"access": "public", "ctype": "Connection", "signature": "()", "type": "*Connection, error"}),
+		tag("Dial2", 39, "f", F{"access": "public", "ctype": "Connection", "signature": "()", "type": "*Connection, *Struct2"}),
+		tag("Dial3", 42, "f", F{"access": "public", "signature": "()", "type": "*Connection, *Connection"}),
+	}},
+	{filename: "testdata/struct.go", withExtraSymbols: true, tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("Field1", 4, "w", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "type": "int"}),
+		tag("Field2", 4, "w", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "type": "int"}),
+		tag("field3", 5, "w", F{"access": "private", "ctype": "Struct", "scope": "type:Struct", "type": "string"}),
+		tag("field4", 6, "w", F{"access": "private", "ctype": "Struct", "scope": "type:Struct", "type": "*bool"}),
+		tag("Struct", 3, "t", F{"access": "public", "type": "struct"}),
+		tag("Test.Struct", 3, "t", F{"access": "public", "type": "struct"}),
+		tag("Struct", 20, "e", F{"access": "public", "ctype": "TestEmbed", "scope": "type:TestEmbed", "type": "Struct"}),
+		tag("*io.Writer", 21, "e", F{"access": "public", "ctype": "TestEmbed", "scope": "type:TestEmbed", "type": "*io.Writer"}),
+		tag("TestEmbed", 19, "t", F{"access": "public", "type": "struct"}),
+		tag("Test.TestEmbed", 19, "t", F{"access": "public", "type": "struct"}),
+		tag("Struct2", 27, "t", F{"access": "public", "type": "struct"}),
+		tag("Test.Struct2", 27, "t", F{"access": "public", "type": "struct"}),
+		tag("Connection", 36, "t", F{"access": "public", "type": "struct"}),
+		tag("Test.Connection", 36, "t", F{"access": "public", "type": "struct"}),
+		tag("NewStruct", 9, "f", F{"access": "public", "ctype": "Struct", "signature": "()", "type": "*Struct"}),
+		tag("Test.NewStruct", 9, "f", F{"access": "public", "ctype": "Struct", "signature": "()", "type": "*Struct"}),
+		tag("F1", 13, "m", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "signature": "()", "type": "[]bool, [2]*string"}),
+		tag("Struct.F1", 13, "m", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "signature": "()", "type": "[]bool, [2]*string"}),
+		tag("Test.F1", 13, "m", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "signature": "()", "type": "[]bool, [2]*string"}),
+		tag("Test.Struct.F1", 13, "m", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "signature": "()", "type": "[]bool, [2]*string"}),
+		tag("F2", 16, "m", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "signature": "()", "type": "bool"}),
+		tag("Struct.F2", 16, "m", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "signature": "()", "type": "bool"}),
+		tag("Test.Struct.F2", 16, "m", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "signature": "()", "type": "bool"}),
+		tag("Test.F2", 16, "m", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "signature": "()", "type": "bool"}),
+		tag("NewTestEmbed", 24, "f", F{"access": "public", "ctype": "TestEmbed", "signature": "()", "type": "TestEmbed"}),
+		tag("Test.NewTestEmbed", 24, "f", F{"access": "public", "ctype": "TestEmbed", "signature": "()", "type": "TestEmbed"}),
+		tag("NewStruct2", 30, "f", F{"access": "public", "ctype": "Struct2", "signature": "()", "type": "*Struct2, error"}),
+		tag("Test.NewStruct2", 30, "f", F{"access": "public", "ctype": "Struct2", "signature": "()", "type": "*Struct2, error"}),
+		tag("Dial", 33, "f", F{"access": "public", "ctype": "Connection", "signature": "()", "type": "*Connection, error"}),
+		tag("Test.Dial", 33, "f", F{"access": "public", "ctype": "Connection", "signature": "()", "type": "*Connection, error"}),
+		tag("Dial2", 39, "f", F{"access": "public", "ctype": "Connection", "signature": "()", "type": "*Connection, *Struct2"}),
+		tag("Test.Dial2", 39, "f", F{"access": "public", "ctype": "Connection", "signature": "()", "type": "*Connection, *Struct2"}),
+		tag("Dial3", 42, "f", F{"access": "public", "signature": "()", "type": "*Connection, *Connection"}),
+		tag("Test.Dial3", 42, "f", F{"access": "public", "signature": "()", "type": "*Connection, *Connection"}),
+		tag("Test.Field1", 4, "w", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "type": "int"}),
+		tag("Struct.Field1", 4, "w", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "type": "int"}),
+		tag("Test.Struct.Field1", 4, "w", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "type": "int"}),
+		tag("Test.Field2", 4, "w", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "type": "int"}),
+		tag("Struct.Field2", 4, "w", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "type": "int"}),
+		tag("Test.Struct.Field2", 4, "w", F{"access": "public", "ctype": "Struct", "scope": "type:Struct", "type": "int"}),
+		tag("Test.field3", 5, "w", F{"access": "private", "ctype": "Struct", "scope": "type:Struct", "type": "string"}),
+		tag("Struct.field3", 5, "w", F{"access": "private", "ctype": "Struct", "scope": "type:Struct", "type": "string"}),
+		tag("Test.Struct.field3", 5, "w", F{"access": "private", "ctype": "Struct", "scope": "type:Struct", "type": "string"}),
+		tag("Test.field4", 6, "w", F{"access": "private", "ctype": "Struct", "scope": "type:Struct", "type": "*bool"}),
+		tag("Struct.field4", 6, "w", F{"access": "private", "ctype": "Struct", "scope": "type:Struct", "type": "*bool"}),
+		tag("Test.Struct.field4", 6, "w", F{"access": "private", "ctype": "Struct", "scope": "type:Struct", "type": "*bool"}),
+	}},
+	{filename: "testdata/structtags.go", tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("User", 3, "t", F{"access": "public", "type": "struct"}),
+		tag("ID", 4, "w", F{"access": "public", "ctype": "User", "scope": "type:User", "structTag": `json:"user_id" db:"uid"`, "type": "int"}),
+		tag("Name", 5, "w", F{"access": "public", "ctype": "User", "scope": "type:User", "structTag": `json:"name,omitempty" yaml:"full_name"`, "type": "string"}),
+		tag("Password", 6, "w", F{"access": "public", "ctype": "User", "scope": "type:User", "structTag": `json:"-"`, "type": "string"}),
+		tag("Email", 7, "w", F{"access": "public", "ctype": "User", "scope": "type:User", "structTag": `json:",omitempty"`, "type": "string"}),
+		tag("Address", 8, "e", F{"access": "public", "ctype": "User", "scope": "type:User", "structTag": `yaml:"address"`, "type": "Address"}),
+		tag("age", 9, "w", F{"access": "private", "ctype": "User", "scope": "type:User", "type": "int"}),
+	}},
+	{filename: "testdata/structtags.go", extra: FieldSet{KeyTags: true}, tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("User", 3, "t", F{"access": "public", "type": "struct"}),
+		tag("ID", 4, "w", F{"access": "public", "ctype": "User", "scope": "type:User", "structTag": `json:"user_id" db:"uid"`, "type": "int"}),
+		tag("user_id", 4, "k", F{"scope": "field:User.ID", "structTag": `json:"user_id" db:"uid"`}),
+		tag("uid", 4, "k", F{"scope": "field:User.ID", "structTag": `json:"user_id" db:"uid"`}),
+		tag("Name", 5, "w", F{"access": "public", "ctype": "User", "scope": "type:User", "structTag": `json:"name,omitempty" yaml:"full_name"`, "type": "string"}),
+		tag("name", 5, "k", F{"scope": "field:User.Name", "structTag": `json:"name,omitempty" yaml:"full_name"`}),
+		tag("full_name", 5, "k", F{"scope": "field:User.Name", "structTag": `json:"name,omitempty" yaml:"full_name"`}),
+		tag("Password", 6, "w", F{"access": "public", "ctype": "User", "scope": "type:User", "structTag": `json:"-"`, "type": "string"}),
+		tag("Email", 7, "w", F{"access": "public", "ctype": "User", "scope": "type:User", "structTag": `json:",omitempty"`, "type": "string"}),
+		tag("Address", 8, "e", F{"access": "public", "ctype": "User", "scope": "type:User", "structTag": `yaml:"address"`, "type": "Address"}),
+		tag("address", 8, "k", F{"scope": "field:User.Address", "structTag": `yaml:"address"`}),
+		tag("age", 9, "w", F{"access": "private", "ctype": "User", "scope": "type:User", "type": "int"}),
+	}},
+	{filename: "testdata/structtags.go", extra: FieldSet{KeyTags: true}, separator: "::", tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("User", 3, "t", F{"access": "public", "type": "struct"}),
+		tag("ID", 4, "w", F{"access": "public", "ctype": "User", "scope": "type:User", "structTag": `json:"user_id" db:"uid"`, "type": "int"}),
+		tag("user_id", 4, "k", F{"scope": "field:User::ID", "structTag": `json:"user_id" db:"uid"`}),
+		tag("uid", 4, "k", F{"scope": "field:User::ID", "structTag": `json:"user_id" db:"uid"`}),
+		tag("Name", 5, "w", F{"access": "public", "ctype": "User", "scope": "type:User", "structTag": `json:"name,omitempty" yaml:"full_name"`, "type": "string"}),
+		tag("name", 5, "k", F{"scope": "field:User::Name", "structTag": `json:"name,omitempty" yaml:"full_name"`}),
+		tag("full_name", 5, "k", F{"scope": "field:User::Name", "structTag": `json:"name,omitempty" yaml:"full_name"`}),
+		tag("Password", 6, "w", F{"access": "public", "ctype": "User", "scope": "type:User", "structTag": `json:"-"`, "type": "string"}),
+		tag("Email", 7, "w", F{"access": "public", "ctype": "User", "scope": "type:User", "structTag": `json:",omitempty"`, "type": "string"}),
+		tag("Address", 8, "e", F{"access": "public", "ctype": "User", "scope": "type:User", "structTag": `yaml:"address"`, "type": "Address"}),
+		tag("address", 8, "k", F{"scope": "field:User::Address", "structTag": `yaml:"address"`}),
+		tag("age", 9, "w", F{"access": "private", "ctype": "User", "scope": "type:User", "type": "int"}),
+	}},
+	{filename: "testdata/type.go", tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("testType", 3, "t", F{"access": "private", "type": "int"}),
+		tag("testArrayType", 4, "t", F{"access": "private", "type": "[4]int"}),
+		tag("testSliceType", 5, "t", F{"access": "private", "type": "[]int"}),
+		tag("testPointerType", 6, "t", F{"access": "private", "type": "*string"}),
+		tag("testFuncType1", 7, "t", F{"access": "private", "type": "func()"}),
+		tag("testFuncType2", 8, "t", F{"access": "private", "type": "func(int) string"}),
+		tag("testMapType", 9, "t", F{"access": "private", "type": "map[string]bool"}),
+		tag("testChanType", 10, "t", F{"access": "private", "type": "chan bool"}),
+	}},
+	{filename: "testdata/type.go", withExtraSymbols: true, tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("testType", 3, "t", F{"access": "private", "type": "int"}),
+		tag("testArrayType", 4, "t", F{"access": "private", "type": "[4]int"}),
+		tag("testSliceType", 5, "t", F{"access": "private", "type": "[]int"}),
+		tag("testPointerType", 6, "t", F{"access": "private", "type": "*string"}),
+		tag("testFuncType1", 7, "t", F{"access": "private", "type": "func()"}),
+		tag("testFuncType2", 8, "t", F{"access": "private", "type": "func(int) string"}),
+		tag("testMapType", 9, "t", F{"access": "private", "type": "map[string]bool"}),
+		tag("testChanType", 10, "t", F{"access": "private", "type": "chan bool"}),
+		tag("Test.testType", 3, "t", F{"access": "private", "type": "int"}),
+		tag("Test.testArrayType", 4, "t", F{"access": "private", "type": "[4]int"}),
+		tag("Test.testSliceType", 5, "t", F{"access": "private", "type": "[]int"}),
+		tag("Test.testPointerType", 6, "t", F{"access": "private", "type": "*string"}),
+		tag("Test.testFuncType1", 7, "t", F{"access": "private", "type": "func()"}),
+		tag("Test.testFuncType2", 8, "t", F{"access": "private", "type": "func(int) string"}),
+		tag("Test.testMapType", 9, "t", F{"access": "private", "type": "map[string]bool"}),
+		tag("Test.testChanType", 10, "t", F{"access": "private", "type": "chan bool"}),
+	}},
+	{filename: "testdata/var.go", tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("variable1", 3, "v", F{"access": "private", "type": "int"}),
+		tag("variable2", 4, "v", F{"access": "private", "type": "string"}),
+		tag("A", 7, "v", F{"access": "public"}),
+		tag("B", 8, "v", F{"access": "public"}),
+		tag("C", 8, "v", F{"access": "public"}),
+		tag("D", 9, "v", F{"access": "public"}),
+	}},
+	{filename: "testdata/var.go", withExtraSymbols: true, tags: []Tag{
+		tag("Test", 1, "p", F{}),
+		tag("variable1", 3, "v", F{"access": "private", "type": "int"}),
+		tag("variable2", 4, "v", F{"access": "private", "type": "string"}),
+		tag("A", 7, "v", F{"access": "public"}),
+		tag("B", 8, "v", F{"access": "public"}),
+		tag("C", 8, "v", F{"access": "public"}),
+		tag("D", 9, "v", F{"access": "public"}),
+		tag("Test.variable1", 3, "v", F{"access": "private", "type": "int"}),
+		tag("Test.variable2", 4, "v", F{"access": "private", "type": "string"}),
+		tag("Test.A", 7, "v", F{"access": "public"}),
+		tag("Test.B", 8, "v", F{"access": "public"}),
+		tag("Test.C", 8, "v", F{"access": "public"}),
+		tag("Test.D", 9, "v", F{"access": "public"}),
+	}},
+	{filename: "testdata/references.go", extra: FieldSet{ReferenceTags: true}, tags: []Tag{
+		tag("Test", 1, "p", F{"roles": "def"}),
+		tag("Point", 3, "t", F{"access": "public", "roles": "def", "type": "struct"}),
+		tag("X", 4, "w", F{"access": "public", "ctype": "Point", "roles": "def", "scope": "type:Point", "type": "int"}),
+		tag("Y", 4, "w", F{"access": "public", "ctype": "Point", "roles": "def", "scope": "type:Point", "type": "int"}),
+		tag("Move", 7, "m", F{"access": "public", "ctype": "Point", "roles": "def", "scope": "type:Point", "signature": "(dx int)"}),
+		tag("origin", 11, "v", F{"access": "private", "roles": "def"}),
+		tag("NewPoint", 13, "f", F{"access": "public", "ctype": "Point", "roles": "def", "signature": "()", "type": "*Point"}),
+		tag("shadow", 20, "f", F{"access": "private", "roles": "def", "signature": "()"}),
+		tag("Point", 7, "t", F{"roles": "ref"}),
+		tag("X", 8, "w", F{"roles": "assign"}),
+		tag("Point", 13, "t", F{"roles": "ref"}),
+		tag("Point", 14, "t", F{"roles": "ref"}),
+		tag("X", 14, "w", F{"roles": "assign"}),
+		tag("Move", 15, "m", F{"roles": "call"}),
+		tag("Y", 16, "w", F{"roles": "assign"}),
+		tag("Y", 16, "w", F{"roles": "ref"}),
+		tag("origin", 16, "v", F{"roles": "ref"}),
+		tag("NewPoint", 23, "f", F{"roles": "call"}),
+	}},
+	{filename: "testdata/simple.go", relative: true, basepath: "dir", tags: []Tag{
+		{Name: "main", File: "../testdata/simple.go", Address: "1", Type: "p", Fields: F{"line": "1"}},
+	}},
+	{filename: "testdata/simple.go", withExtraSymbols: true, relative: true, basepath: "dir", tags: []Tag{
+		{Name: "main", File: "../testdata/simple.go", Address: "1", Type: "p", Fields: F{"line": "1"}},
+	}},
+	{filename: "testdata/range.go", minversion: 4, tags: []Tag{
+		tag("main", 1, "p", F{}),
+		tag("fmt", 3, "i", F{}),
+		tag("main", 5, "f", F{"access": "private", "signature": "()"}),
+	}},
+	{filename: "testdata/range.go", withExtraSymbols: true, minversion: 4, tags: []Tag{
+		tag("main", 1, "p", F{}),
+		tag("fmt", 3, "i", F{}),
+		tag("main", 5, "f", F{"access": "private", "signature": "()"}),
+		tag("main.main", 5, "f", F{"access": "private", "signature": "()"}),
+	}},
+}
+
+func TestParse(t *testing.T) {
+	for _, testCase := range testCases {
+		if testCase.minversion > 0 && extractVersionCode(runtime.Version()) < testCase.minversion {
+			t.Skipf("[%s] skipping test. Version is %s, but test requires at least go1.%d", testCase.filename, runtime.Version(), testCase.minversion)
+			continue
+		}
+
+		basepath, err := filepath.Abs(testCase.basepath)
+		if err != nil {
+			t.Errorf("[%s] could not determine base path: %s\n", testCase.filename, err)
+			continue
+		}
+
+		extra := FieldSet{}
+		for field, ok := range testCase.extra {
+			extra[field] = ok
+		}
+		if testCase.withExtraSymbols {
+			extra[ExtraTags] = true
+		}
+
+		tags, err := Parse(testCase.filename, Options{Relative: testCase.relative, BasePath: basepath, Extra: extra, Separator: testCase.separator})
+		if err != nil {
+			t.Errorf("[%s] Parse error: %s", testCase.filename, err)
+			continue
+		}
+
+		sort.Sort(TagSlice(tags))
+		sort.Sort(TagSlice(testCase.tags))
+
+		if len(tags) != len(testCase.tags) {
+			t.Errorf("[%s] len(tags) == %d, want %d", testCase.filename, len(tags), len(testCase.tags))
+			continue
+		}
+
+		for i, tag := range testCase.tags {
+			if len(tag.File) == 0 {
+				tag.File = testCase.filename
+			}
+			if tags[i].String() != tag.String() {
+				t.Errorf("[%s] tag(%d)\n  is:%s\nwant:%s", testCase.filename, i, tags[i].String(), tag.String())
+			}
+		}
+	}
+}
+
+func tag(n string, l int, t TagType, fields F) (tag Tag) {
+	tag = Tag{
+		Name:    n,
+		File:    "",
+		Address: strconv.Itoa(l),
+		Type:    t,
+		Fields:  fields,
+	}
+
+	tag.Fields["line"] = tag.Address
+
+	return
+}
+
+func extractVersionCode(version string) int {
+	matches := goVersionRegexp.FindAllStringSubmatch(version, -1)
+	if len(matches) == 0 || len(matches[0]) < 2 {
+		return 0
+	}
+	n, _ := strconv.Atoi(matches[0][1])
+	return n
+}
+
+func TestParsePackage(t *testing.T) {
+	files := []string{"testdata/package/server.go", "testdata/package/server_ctor.go"}
+	expected := []Tag{
+		tag("server", 1, "p", F{}),
+		tag("addr", 4, "w", F{"access": "private", "ctype": "Server", "scope": "type:Server", "type": "string"}),
+		tag("Server", 3, "t", F{"access": "public", "type": "struct"}),
+		tag("Start", 7, "m", F{"access": "public", "ctype": "Server", "scope": "type:Server", "signature": "()", "type": "error"}),
+		tag("server", 1, "p", F{}),
+		tag("NewServer", 3, "f", F{"access": "public", "ctype": "Server", "signature": "(addr string)", "type": "*Server"}),
+		tag("Stop", 6, "m", F{"access": "public", "ctype": "Server", "scope": "type:Server", "signature": "()"}),
+	}
+	for i := range expected {
+		expected[i].File = files[0]
+		if i > 3 {
+			expected[i].File = files[1]
+		}
+	}
+
+	tags, err := ParsePackage(files, Options{})
+	if err != nil {
+		t.Fatalf("ParsePackage error: %s", err)
+	}
+
+	sort.Sort(TagSlice(tags))
+	sort.Sort(TagSlice(expected))
+
+	if len(tags) != len(expected) {
+		t.Fatalf("len(tags) == %d, want %d", len(tags), len(expected))
+	}
+	for i, tag := range expected {
+		if tags[i].String() != tag.String() {
+			t.Errorf("tag(%d)\n  is:%s\nwant:%s", i, tags[i].String(), tag.String())
+		}
+	}
+}
+
+func TestParsePackageError(t *testing.T) {
+	files := []string{"testdata/package/server.go", "testdata/package/missing.go", "testdata/package/other.go"}
+
+	tags, err := ParsePackage(files, Options{})
+	if err == nil {
+		t.Fatal("expected ParsePackage to return an error")
+	}
+	if len(tags) != 4 {
+		t.Errorf("len(tags) == %d, want %d", len(tags), 4)
+	}
+	for _, name := range files[1:] {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("expected error to mention %s, got: %s", name, err)
+		}
+	}
+}
+
+func TestGroupPackages(t *testing.T) {
+	files := []string{
+		"testdata/package/server.go",
+		"testdata/const.go",
+		"testdata/package/server_ext_test.go",
+		"testdata/package/server_ctor.go",
+		"testdata/func.go",
+		"testdata/missing.go",
+	}
+	expected := [][]string{
+		{"testdata/package/server.go", "testdata/package/server_ctor.go"},
+		{"testdata/const.go", "testdata/func.go"},
+		{"testdata/package/server_ext_test.go"},
+		{"testdata/missing.go"},
+	}
+
+	groups := GroupPackages(files)
+	if fmt.Sprint(groups) != fmt.Sprint(expected) {
+		t.Errorf("GroupPackages()\n  is:%v\nwant:%v", groups, expected)
+	}
+}
+
+func TestParseEndColumn(t *testing.T) {
+	var tests = []struct {
+		filename string
+		name     string
+		line     int
+		column   int
+		end      int
+	}{
+		{"testdata/struct.go", "Struct", 3, 6, 7},
+		{"testdata/struct.go", "Field2", 4, 10, 0},
+		{"testdata/struct.go", "NewStruct", 9, 6, 11},
+		{"testdata/struct.go", "F1", 13, 17, 14},
+		{"testdata/interface.go", "Interface", 3, 6, 7},
+		{"testdata/interface.go", "InterfaceMethod", 4, 2, 4},
+		{"testdata/const.go", "Constant", 3, 7, 0},
+	}
+
+	for _, test := range tests {
+		tags, err := Parse(test.filename, Options{})
+		if err != nil {
+			t.Errorf("[%s] Parse error: %s", test.filename, err)
+			continue
+		}
+
+		var found bool
+		for _, tag := range tags {
+			if tag.Name != test.name || tag.Address != strconv.Itoa(test.line) {
+				continue
+			}
+			found = true
+			if tag.Column != test.column || tag.EndLine != test.end {
+				t.Errorf("[%s] %s: column %d, end %d, want column %d, end %d", test.filename, test.name, tag.Column, tag.EndLine, test.column, test.end)
+			}
+		}
+		if !found {
+			t.Errorf("[%s] tag %s not found", test.filename, test.name)
+		}
+	}
+}
+
+func TestParseEndColumnFields(t *testing.T) {
+	set, err := ParseFields("+e{column}")
+	if err != nil {
+		t.Fatalf("unexpected error from ParseFields: %s", err)
+	}
+
+	tags, err := Parse("testdata/func.go", Options{})
+	if err != nil {
+		t.Fatalf("Parse error: %s", err)
+	}
+
+	for _, tag := range tags {
+		if tag.Name != "Function1" {
+			continue
+		}
+		set.Apply(tag)
+		if tag.Fields[End] != "4" || tag.Fields[ColumnField] != "6" {
+			t.Errorf("unexpected fields end:%q column:%q, want end:4 column:6", tag.Fields[End], tag.Fields[ColumnField])
+		}
+		return
+	}
+	t.Error("tag Function1 not found")
+}
+
+func TestParseSource(t *testing.T) {
+	src := []byte("package p\n\nfunc F() int { return 0 }\n")
+
+	fromSource, err := ParseSource("p.go", src, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error from ParseSource: %s", err)
+	}
+	fromReader, err := ParseReader("p.go", bytes.NewReader(src), Options{})
+	if err != nil {
+		t.Fatalf("unexpected error from ParseReader: %s", err)
+	}
+
+	expected := []Tag{
+		tag("p", 1, "p", F{}),
+		tag("F", 3, "f", F{"access": "public", "signature": "()", "type": "int"}),
+	}
+	for _, tags := range [][]Tag{fromSource, fromReader} {
+		if len(tags) != len(expected) {
+			t.Fatalf("len(tags) == %d, want %d", len(tags), len(expected))
+		}
+		for i, tag := range expected {
+			tag.File = "p.go"
+			if tags[i].String() != tag.String() {
+				t.Errorf("tag(%d)\n  is:%s\nwant:%s", i, tags[i].String(), tag.String())
+			}
+		}
+	}
+
+	if _, err := ParseSource("p.go", []byte("package"), Options{}); err == nil {
+		t.Error("expected ParseSource to return error")
+	}
+}
+
+func TestParseTypeCheck(t *testing.T) {
+	filename := "testdata/implements/shape.go"
+	expected := []Tag{
+		tag("Shape", 5, "n", F{"access": "public", "implementedBy": "Square,Tile", "type": "interface"}),
+		tag("Named", 9, "n", F{"access": "public", "implementedBy": "Tile", "type": "interface"}),
+		tag("Square", 14, "t", F{"access": "public", "implements": "Shape,io.Writer", "type": "struct"}),
+		tag("Tile", 22, "t", F{"access": "public", "implements": "Named,Shape,io.Writer", "type": "struct"}),
+		tag("Error", 29, "t", F{"access": "public", "implements": "error", "type": "string"}),
+		tag("Area", 18, "m", F{"access": "public", "ctype": "Tile", "scope": "type:Tile", "signature": "()", "type": "float64"}),
+		tag("Write", 20, "m", F{"access": "public", "ctype": "Tile", "scope": "type:Tile", "signature": "(p []byte)", "type": "int, error"}),
+	}
+
+	tags, err := Parse(filename, Options{TypeCheck: true})
+	if err != nil {
+		t.Fatalf("Parse error: %s", err)
+	}
+
+	found := make(map[string]bool)
+	for _, tag := range tags {
+		found[tag.String()] = true
+	}
+	for _, tag := range expected {
+		tag.File = filename
+		if !found[tag.String()] {
+			t.Errorf("expected tag not found:\n%s", tag.String())
+		}
+	}
+
+	// promoted methods are only added to the embedding type
+	var promoted int
+	for _, tag := range tags {
+		if tag.Type == Method && tag.Fields[Scope] == "type:Tile" && tag.Name != "Name" {
+			promoted++
+		}
+	}
+	if promoted != 2 {
+		t.Errorf("found %d promoted methods, want 2", promoted)
+	}
+
+	// without type checking, there are no implements fields
+	tags, err = Parse(filename, Options{})
+	if err != nil {
+		t.Fatalf("Parse error: %s", err)
+	}
+	for _, tag := range tags {
+		if _, ok := tag.Fields[Implements]; ok {
+			t.Errorf("unexpected implements field without type checking:\n%s", tag.String())
+		}
+	}
+}
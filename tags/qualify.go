@@ -0,0 +1,156 @@
+package tags
+
+import (
+	"bufio"
+	"go/ast"
+	"go/build"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// scopeOf returns the value of the scope field of a tag declared inside the
+// tag with type kind and name name, e.g. "type:Struct".
+func scopeOf(kind TagType, name string) string {
+	return kind.Name() + ":" + name
+}
+
+// scopeName returns the name of the scope tag is declared in, or an empty
+// string if tag is not declared inside another tag.
+func scopeName(tag Tag) string {
+	scope := tag.Fields[Scope]
+	if idx := strings.IndexByte(scope, ':'); idx >= 0 {
+		return scope[idx+1:]
+	}
+	return ""
+}
+
+// isLocal reports whether tag is declared inside a function body.
+func isLocal(tag Tag) bool {
+	scope := tag.Fields[Scope]
+	return strings.HasPrefix(scope, Function.Name()+":") || strings.HasPrefix(scope, Method.Name()+":")
+}
+
+// qualifyTags adds a copy with a qualified name of each declaration tag in
+// p.tags, for the extra tags selected in p.opts.Extra. With ExtraTags, the
+// names are qualified with the package name and with the scope of the tag,
+// e.g. pkg.Name, Type.Field and pkg.Type.Field. With ExtraImportPathTags, the
+// names are qualified with the import path of the package and the scope of
+// the tag, e.g. github.com/user/repo/pkg.Type.Field.
+func (p *tagParser) qualifyTags(files []*ast.File) {
+	if !p.opts.Extra.Includes(ExtraTags) && !p.opts.Extra.Includes(ExtraImportPathTags) {
+		return
+	}
+
+	pkgNames := make(map[string]string)
+	importPaths := make(map[string]string)
+	for _, f := range files {
+		filename := p.fset.File(f.Pos()).Name()
+		name := p.opts.FileName(filename)
+		pkgNames[name] = f.Name.Name
+		if p.opts.Extra.Includes(ExtraImportPathTags) {
+			importPaths[name] = packageImportPath(filepath.Dir(filename))
+		}
+	}
+
+	for _, tag := range p.tags {
+		switch tag.Type {
+		case Package, Import, Embedded, TypeParam, Parameter, Label, Key, Directive:
+			continue
+		}
+		if isLocal(tag) || isReference(tag) {
+			continue
+		}
+
+		file := tag.File
+		if _, ok := pkgNames[file]; !ok {
+			// promoted methods may be declared in a file of another package
+			file = p.opts.FileName(p.fset.File(files[0].Pos()).Name())
+		}
+		for _, name := range p.qualifiedNames(tag, pkgNames[file], importPaths[file]) {
+			qualified := tag
+			qualified.Name = name
+			p.tags = append(p.tags, qualified)
+		}
+	}
+}
+
+// qualifiedNames returns the qualified names of tag, which is declared in the
+// package with name pkgName and import path importPath.
+func (p *tagParser) qualifiedNames(tag Tag, pkgName, importPath string) []string {
+	var names []string
+	scope := scopeName(tag)
+	if p.opts.Extra.Includes(ExtraTags) {
+		names = append(names, p.qualify(pkgName, tag.Name))
+		if scope != "" {
+			names = append(names, p.qualify(scope, tag.Name), p.qualify(pkgName, scope, tag.Name))
+		}
+	}
+	if p.opts.Extra.Includes(ExtraImportPathTags) && importPath != "" {
+		names = append(names, p.qualify(importPath, scope, tag.Name))
+	}
+	return names
+}
+
+// qualify joins the non-empty names using the separator in the options of p.
+func (p *tagParser) qualify(names ...string) string {
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		if name != "" {
+			parts = append(parts, name)
+		}
+	}
+	return strings.Join(parts, p.opts.Separator)
+}
+
+// packageImportPath returns the import path of the package in dir. It is
+// derived from the module path in the nearest go.mod file, or from the
+// location of dir in GOPATH. If neither is found, an empty string is
+// returned.
+func packageImportPath(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	for d := dir; ; d = filepath.Dir(d) {
+		if mod := modulePath(filepath.Join(d, "go.mod")); mod != "" {
+			rel, err := filepath.Rel(d, dir)
+			if err != nil {
+				return ""
+			}
+			return path.Join(mod, filepath.ToSlash(rel))
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	for _, root := range filepath.SplitList(build.Default.GOPATH) {
+		rel, err := filepath.Rel(filepath.Join(root, "src"), dir)
+		if err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return ""
+}
+
+// modulePath returns the module path declared in the go.mod file gomod, or an
+// empty string if it cannot be read.
+func modulePath(gomod string) string {
+	f, err := os.Open(gomod)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], "\"`")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,102 @@
+package tags
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestQualifiedNames(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/repo\n\ngo 1.18\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, "pkg", "pkg.go")
+	src := "package pkg\n\ntype T struct {\n\tF int\n\tG struct{ H int }\n}\n\nfunc (T) M() {}\n\ntype I interface{ N() }\n\nconst C = 1\n"
+	if err := os.WriteFile(filename, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		separator string
+		extra     FieldSet
+		want      []string
+	}{
+		{".", FieldSet{}, []string{"C", "F", "G", "H", "I", "M", "N", "T"}},
+		{".", FieldSet{ExtraTags: true}, []string{
+			"C", "F", "G", "H", "I", "I.N", "M", "N", "T", "T.F", "T.G", "T.G.H", "T.M",
+			"pkg.C", "pkg.F", "pkg.G", "pkg.H", "pkg.I", "pkg.I.N", "pkg.M", "pkg.N",
+			"pkg.T", "pkg.T.F", "pkg.T.G", "pkg.T.G.H", "pkg.T.M",
+		}},
+		{"::", FieldSet{ExtraImportPathTags: true}, []string{
+			"C", "F", "G", "H", "I", "M", "N", "T",
+			"example.com/repo/pkg::C", "example.com/repo/pkg::I", "example.com/repo/pkg::I::N",
+			"example.com/repo/pkg::T", "example.com/repo/pkg::T::F", "example.com/repo/pkg::T::G",
+			"example.com/repo/pkg::T::G::H", "example.com/repo/pkg::T::M",
+		}},
+	}
+
+	for _, test := range tests {
+		tags, err := Parse(filename, Options{Extra: test.extra, Separator: test.separator})
+		if err != nil {
+			t.Fatalf("unexpected error from Parse: %s", err)
+		}
+
+		var names []string
+		for _, tag := range tags {
+			if tag.Type != Package {
+				names = append(names, tag.Name)
+			}
+		}
+		sort.Strings(names)
+
+		if len(names) != len(test.want) {
+			t.Errorf("[%v] names\n  is:%v\nwant:%v", test.extra, names, test.want)
+			continue
+		}
+		for i := range names {
+			if names[i] != test.want[i] {
+				t.Errorf("[%v] names\n  is:%v\nwant:%v", test.extra, names, test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestPackageImportPath(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module \"example.com/repo\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(dir, "a", "b")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if path := packageImportPath(dir); path != "example.com/repo" {
+		t.Errorf("packageImportPath(%q) = %q, want %q", dir, path, "example.com/repo")
+	}
+	if path := packageImportPath(sub); path != "example.com/repo/a/b" {
+		t.Errorf("packageImportPath(%q) = %q, want %q", sub, path, "example.com/repo/a/b")
+	}
+}
+
+func TestParseExtraSymbols(t *testing.T) {
+	set, err := ParseExtras("+qQl")
+	if err != nil {
+		t.Fatalf("unexpected error from ParseExtras: %s", err)
+	}
+	if !set.Includes(ExtraTags) || !set.Includes(ExtraImportPathTags) || !set.Includes(LocalTags) {
+		t.Errorf("expected set to include %s, %s and %s", ExtraTags, ExtraImportPathTags, LocalTags)
+	}
+
+	if _, err := ParseExtras("+x"); err == nil {
+		t.Error("expected ParseExtras to return error")
+	} else if err.Error() != "invalid fields: +x" {
+		t.Errorf("unexpected error %q", err)
+	}
+}
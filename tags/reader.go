@@ -0,0 +1,434 @@
+package tags
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidTagLine is an error returned when attempting to parse a line that
+// is not in the tags file format.
+type ErrInvalidTagLine struct {
+	Line string
+}
+
+func (e ErrInvalidTagLine) Error() string {
+	return fmt.Sprintf("invalid tag line: %s", e.Line)
+}
+
+// ParseTagLine parses line, a single line of a tags file, into a tag. The
+// address of the tag can be a line number, a search pattern or both. The
+// extension fields are stored in the Fields of the tag, the kind field is
+// stored in its Type. If the kind is a long name, it is converted to the kind
+// letter.
+func ParseTagLine(line string) (Tag, error) {
+	line = strings.TrimRight(line, "\r\n")
+	parts := strings.SplitN(line, "\t", 3)
+	if len(parts) < 3 || len(parts[0]) == 0 || strings.HasPrefix(line, "!_") {
+		return Tag{}, ErrInvalidTagLine{line}
+	}
+
+	address, rest, ok := splitAddress(parts[2])
+	if !ok {
+		return Tag{}, ErrInvalidTagLine{line}
+	}
+
+	tag := Tag{
+		Name:    parts[0],
+		File:    parts[1],
+		Address: address,
+		Fields:  make(map[TagField]string),
+	}
+	if pattern := addressPattern(address); len(pattern) > 0 {
+		tag.Source = patternSource(pattern)
+	}
+
+	if len(rest) == 0 {
+		return tag, nil
+	}
+	if !strings.HasPrefix(rest, ";\"") {
+		return Tag{}, ErrInvalidTagLine{line}
+	}
+
+	for _, field := range strings.Split(rest[2:], "\t") {
+		if len(field) == 0 {
+			continue
+		}
+		idx := strings.IndexByte(field, ':')
+		if idx < 0 {
+			tag.Type = kindType(field)
+			continue
+		}
+
+		key, value := field[:idx], unescapeField(field[idx+1:])
+		if TagField(key) == Kind {
+			tag.Type = kindType(value)
+			continue
+		}
+		tag.Fields[TagField(key)] = value
+	}
+	return tag, nil
+}
+
+// splitAddress splits s, the part of a tag line following the file name, into
+// the address and the remainder of the line.
+func splitAddress(s string) (address, rest string, ok bool) {
+	end := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if end < 0 {
+		return s, "", len(s) > 0
+	}
+	if end > 0 && !strings.HasPrefix(s[end:], ";/") && !strings.HasPrefix(s[end:], ";?") {
+		return s[:end], s[end:], true
+	}
+	if end > 0 {
+		// combined line number and pattern
+		end++
+	}
+
+	if end >= len(s) || (s[end] != '/' && s[end] != '?') {
+		return "", "", false
+	}
+	delim := s[end]
+	for i := end + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case delim:
+			return s[:i+1], s[i+1:], true
+		}
+	}
+	return "", "", false
+}
+
+// patternSource returns the source line that search pattern matches, i.e. the
+// pattern without delimiters, anchors and escape characters.
+func patternSource(pattern string) string {
+	if len(pattern) < 2 {
+		return ""
+	}
+	pattern = pattern[1 : len(pattern)-1]
+	pattern = strings.TrimPrefix(pattern, "^")
+	if strings.HasSuffix(pattern, "$") && !strings.HasSuffix(pattern, "\\$") {
+		pattern = pattern[:len(pattern)-1]
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] == '\\' && i+1 < len(pattern) {
+			i++
+		}
+		b.WriteByte(pattern[i])
+	}
+	return b.String()
+}
+
+// unescapeField returns the value of an extension field with the escape
+// sequences used by universal-ctags replaced by the characters they represent.
+func unescapeField(value string) string {
+	if !strings.Contains(value, "\\") {
+		return value
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			switch value[i+1] {
+			case 't':
+				b.WriteByte('\t')
+				i++
+				continue
+			case 'r':
+				b.WriteByte('\r')
+				i++
+				continue
+			case 'n':
+				b.WriteByte('\n')
+				i++
+				continue
+			case '\\':
+				b.WriteByte('\\')
+				i++
+				continue
+			}
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
+
+// kindType returns the tag type of kind, which is either a kind letter or a
+// long kind name.
+func kindType(kind string) TagType {
+	if len(kind) > 1 {
+		if info, ok := lookupKind("", kind); ok {
+			return info.letter
+		}
+	}
+	return TagType(kind)
+}
+
+// parseMetaTagLine parses line, a pseudo tag line of a tags file.
+func parseMetaTagLine(line string) (MetaTag, error) {
+	line = strings.TrimRight(line, "\r\n")
+	if !strings.HasPrefix(line, "!_") {
+		return MetaTag{}, ErrInvalidTagLine{line}
+	}
+
+	parts := strings.SplitN(line[2:], "\t", 3)
+	m := MetaTag{Name: parts[0]}
+	if len(parts) > 1 {
+		m.Value = parts[1]
+	}
+	if len(parts) > 2 {
+		m.Comment = strings.TrimSuffix(strings.TrimPrefix(parts[2], "/"), "/")
+	}
+	return m, nil
+}
+
+// ReadTags reads a tags file from r and returns its pseudo tags and tags.
+func ReadTags(r io.Reader) ([]MetaTag, []Tag, error) {
+	var metaTags []MetaTag
+	var tags []Tag
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "!_") {
+			m, err := parseMetaTagLine(line)
+			if err != nil {
+				return nil, nil, err
+			}
+			metaTags = append(metaTags, m)
+			continue
+		}
+
+		tag, err := ParseTagLine(line)
+		if err != nil {
+			return nil, nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return metaTags, tags, scanner.Err()
+}
+
+// Values of the TAG_FILE_SORTED pseudo tag.
+const (
+	unsorted = 0
+	sorted   = 1
+	foldcase = 2
+)
+
+// TagFile is a tags file opened for looking up tags. If the file is sorted,
+// as indicated by its TAG_FILE_SORTED pseudo tag, tags are found using a
+// binary search, without reading the entire file.
+type TagFile struct {
+	MetaTags []MetaTag // pseudo tags at the start of the file
+
+	r      io.ReaderAt
+	size   int64
+	sorted int
+	closer io.Closer
+}
+
+// OpenTagFile opens the tags file filename for looking up tags. The file
+// should be closed when it is no longer used.
+func OpenTagFile(filename string) (*TagFile, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	t, err := NewTagFile(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	t.closer = f
+	return t, nil
+}
+
+// NewTagFile returns a TagFile that reads a tags file of size bytes from r.
+func NewTagFile(r io.ReaderAt, size int64) (*TagFile, error) {
+	t := &TagFile{r: r, size: size}
+
+	var off int64
+	for off < size {
+		line, next, err := t.lineAt(off)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(line, "!_") {
+			break
+		}
+
+		m, err := parseMetaTagLine(line)
+		if err != nil {
+			return nil, err
+		}
+		if m.Name == "TAG_FILE_SORTED" {
+			t.sorted, _ = strconv.Atoi(m.Value)
+		}
+		t.MetaTags = append(t.MetaTags, m)
+		off = next
+	}
+	return t, nil
+}
+
+// Close closes the tags file, if it was opened by OpenTagFile.
+func (t *TagFile) Close() error {
+	if t.closer == nil {
+		return nil
+	}
+	return t.closer.Close()
+}
+
+// Lookup returns the tags named name.
+func (t *TagFile) Lookup(name string) ([]Tag, error) {
+	return t.find(name, false)
+}
+
+// LookupPrefix returns the tags whose name starts with prefix.
+func (t *TagFile) LookupPrefix(prefix string) ([]Tag, error) {
+	return t.find(prefix, true)
+}
+
+// find returns the tags whose name is key, or starts with key if prefix is
+// set.
+func (t *TagFile) find(key string, prefix bool) ([]Tag, error) {
+	var off int64
+	if t.sorted != unsorted {
+		var err error
+		if off, err = t.search(key); err != nil {
+			return nil, err
+		}
+	}
+
+	var tags []Tag
+	r := bufio.NewReader(io.NewSectionReader(t.r, off, t.size-off))
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if line == "" {
+			break
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if strings.HasPrefix(line, "!_") {
+			continue
+		}
+		name := line
+		if idx := strings.IndexByte(line, '\t'); idx >= 0 {
+			name = line[:idx]
+		}
+
+		if t.match(name, key, prefix) {
+			tag, err := ParseTagLine(line)
+			if err != nil {
+				return nil, err
+			}
+			tags = append(tags, tag)
+		} else if t.sorted != unsorted && t.compare(name, key) > 0 {
+			// the names of all following tags are greater than key, and do
+			// not start with key either
+			break
+		}
+	}
+	return tags, nil
+}
+
+// search returns the offset of the first line in the sorted tags file whose
+// name is not less than key.
+func (t *TagFile) search(key string) (int64, error) {
+	lo, hi := int64(0), t.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		start, err := t.lineStart(mid)
+		if err != nil {
+			return 0, err
+		}
+		if start >= t.size {
+			hi = mid
+			continue
+		}
+
+		line, _, err := t.lineAt(start)
+		if err != nil {
+			return 0, err
+		}
+		name := line
+		if idx := strings.IndexByte(line, '\t'); idx >= 0 {
+			name = line[:idx]
+		}
+
+		if t.compare(name, key) < 0 {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return t.lineStart(lo)
+}
+
+// compare compares name and key in the sort order of the tags file. Files
+// sorted with folded case are sorted as if all names were upper case.
+func (t *TagFile) compare(name, key string) int {
+	if t.sorted == foldcase {
+		name, key = strings.ToUpper(name), strings.ToUpper(key)
+	}
+	return strings.Compare(name, key)
+}
+
+// match reports whether name matches key, or starts with key if prefix is set.
+func (t *TagFile) match(name, key string, prefix bool) bool {
+	if t.sorted == foldcase {
+		name, key = strings.ToUpper(name), strings.ToUpper(key)
+	}
+	if prefix {
+		return strings.HasPrefix(name, key)
+	}
+	return name == key
+}
+
+// lineStart returns the offset of the first line starting at or after off.
+func (t *TagFile) lineStart(off int64) (int64, error) {
+	if off == 0 {
+		return 0, nil
+	}
+	_, next, err := t.lineAt(off - 1)
+	return next, err
+}
+
+// lineAt returns the line starting at off, without line ending, and the offset
+// of the next line. The line is read in small chunks, so that a binary search
+// only reads the lines it compares.
+func (t *TagFile) lineAt(off int64) (string, int64, error) {
+	var line []byte
+	buf := make([]byte, 256)
+	for pos := off; pos < t.size; {
+		n, err := t.r.ReadAt(buf, pos)
+		if idx := bytes.IndexByte(buf[:n], '\n'); idx >= 0 {
+			line = append(line, buf[:idx+1]...)
+			break
+		}
+		line = append(line, buf[:n]...)
+		pos += int64(n)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return "", 0, err
+		}
+	}
+	return strings.TrimRight(string(line), "\r\n"), off + int64(len(line)), nil
+}
@@ -0,0 +1,240 @@
+package tags
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParseTagLine(t *testing.T) {
+	var tests = []struct {
+		line string
+		tag  Tag
+	}{
+		{
+			"Function1\tfile.go\t3;\"\tf\taccess:public\tline:3\tsignature:()",
+			Tag{Name: "Function1", File: "file.go", Address: "3", Type: Function, Fields: F{"access": "public", "line": "3", "signature": "()"}},
+		},
+		{
+			"main\tmain.go\t/^func main() {$/;\"\tkind:function\tline:5",
+			Tag{Name: "main", File: "main.go", Address: "/^func main() {$/", Type: Function, Fields: F{"line": "5"}, Source: "func main() {"},
+		},
+		{
+			"path\tp.go\t7;/^var path = \"a\\/b;\\\"\"$/;\"\tv\ttype:string",
+			Tag{Name: "path", File: "p.go", Address: "7;/^var path = \"a\\/b;\\\"\"$/", Type: Variable, Fields: F{"type": "string"}, Source: "var path = \"a/b;\"\""},
+		},
+		{
+			"fmt\tf.go\t3;\"\ti\tfile:\tsignature:a\\tb\\\\c",
+			Tag{Name: "fmt", File: "f.go", Address: "3", Type: Import, Fields: F{"file": "", "signature": "a\tb\\c"}},
+		},
+		{
+			"T\tt.go\t/^type T struct {/",
+			Tag{Name: "T", File: "t.go", Address: "/^type T struct {/", Fields: F{}, Source: "type T struct {"},
+		},
+	}
+
+	for _, test := range tests {
+		tag, err := ParseTagLine(test.line)
+		if err != nil {
+			t.Errorf("[%s] unexpected error from ParseTagLine: %s", test.line, err)
+			continue
+		}
+		if tag.String() != test.tag.String() || tag.Source != test.tag.Source {
+			t.Errorf("ParseTagLine(%q)\n  is:%s (%q)\nwant:%s (%q)", test.line, tag.String(), tag.Source, test.tag.String(), test.tag.Source)
+		}
+	}
+}
+
+func TestParseTagLineRoundTrip(t *testing.T) {
+	tags := []Tag{
+		{Name: "Tab", File: "c.go", Address: "3", Type: Constant, Fields: F{"line": "3", "value": "\"a\tb\\\\c\""}},
+		{Name: "Text", File: "c.go", Address: "4", Type: Constant, Fields: F{"line": "4", "value": "\"line1\nline2\r\n\""}},
+		{Name: "Path", File: "c.go", Address: "5", Type: Constant, Fields: F{"line": "5", "value": "C:\\dir\\t", "doc": "ends in\\"}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCtags(&buf, nil, tags, WriteOptions{}); err != nil {
+		t.Fatalf("unexpected error from WriteCtags: %s", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(tags) {
+		t.Fatalf("WriteCtags wrote %d lines, want %d:\n%s", len(lines), len(tags), buf.String())
+	}
+
+	for i, line := range lines {
+		tag, err := ParseTagLine(line)
+		if err != nil {
+			t.Errorf("unexpected error from ParseTagLine(%q): %s", line, err)
+			continue
+		}
+		for k, v := range tags[i].Fields {
+			if tag.Fields[k] != v {
+				t.Errorf("[%s] field %s is %q, want %q", tags[i].Name, k, tag.Fields[k], v)
+			}
+		}
+	}
+}
+
+func TestParseTagLineInvalid(t *testing.T) {
+	for _, line := range []string{
+		"",
+		"name",
+		"name\tfile.go",
+		"!_TAG_FILE_FORMAT\t2",
+		"name\tfile.go\t/^unterminated",
+		"name\tfile.go\t3\tf",
+	} {
+		if _, err := ParseTagLine(line); err == nil {
+			t.Errorf("expected ParseTagLine(%q) to return error", line)
+		} else if _, ok := err.(ErrInvalidTagLine); !ok {
+			t.Errorf("expected ParseTagLine(%q) to return error of type ErrInvalidTagLine, got %T", line, err)
+		}
+	}
+}
+
+func TestReadTags(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags, _ := ParseGroups([][]string{files}, 1, Options{})
+	for i, tag := range tags {
+		tags[i].Address = ExcmdCombine.Address(tag)
+	}
+	metaTags := []MetaTag{{"TAG_FILE_FORMAT", "2", ""}, {"TAG_FILE_SORTED", "1", "0=unsorted, 1=sorted"}}
+
+	var b bytes.Buffer
+	if err := WriteCtags(&b, metaTags, tags, WriteOptions{Sort: true}); err != nil {
+		t.Fatalf("unexpected error from WriteCtags: %s", err)
+	}
+
+	readMetaTags, readTags, err := ReadTags(bytes.NewReader(b.Bytes()))
+	if err != nil {
+		t.Fatalf("unexpected error from ReadTags: %s", err)
+	}
+	if len(readMetaTags) != len(metaTags) || readMetaTags[1] != metaTags[1] {
+		t.Errorf("ReadTags meta tags\n  is:%v\nwant:%v", readMetaTags, metaTags)
+	}
+
+	sortTags(tags)
+	if len(readTags) != len(tags) {
+		t.Fatalf("len(tags) == %d, want %d", len(readTags), len(tags))
+	}
+	for i := range tags {
+		if readTags[i].String() != tags[i].String() {
+			t.Errorf("tag(%d)\n  is:%s\nwant:%s", i, readTags[i].String(), tags[i].String())
+		}
+	}
+}
+
+// countingReaderAt counts the number of bytes read from a io.ReaderAt.
+type countingReaderAt struct {
+	r io.ReaderAt
+	n int
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := c.r.ReadAt(p, off)
+	c.n += n
+	return n, err
+}
+
+// tagsFile returns a tags file containing a tag for each name in names,
+// sorted according to sorted.
+func tagsFile(names []string, sorted int) []byte {
+	var lines []string
+	for i, name := range names {
+		lines = append(lines, NewTag(name, "file.go", i+1, Function).String())
+	}
+	switch sorted {
+	case 1:
+		sort.Strings(lines)
+	case 2:
+		sort.Slice(lines, func(i, j int) bool { return strings.ToUpper(lines[i]) < strings.ToUpper(lines[j]) })
+	}
+
+	header := fmt.Sprintf("!_TAG_FILE_FORMAT\t2\n!_TAG_FILE_SORTED\t%d\t/0=unsorted, 1=sorted, 2=foldcase/\n", sorted)
+	return []byte(header + strings.Join(lines, "\n") + "\n")
+}
+
+func TestTagFileLookup(t *testing.T) {
+	var names []string
+	for i := 0; i < 2000; i++ {
+		names = append(names, fmt.Sprintf("Name%04d", i))
+	}
+	names = append(names, "Lookup", "Lookup", "LookupPrefix", "Lookup_x", "lookup", "Look")
+
+	var tests = []struct {
+		key    string
+		prefix bool
+		want   []string
+	}{
+		{"Lookup", false, []string{"Lookup", "Lookup"}},
+		{"Lookup", true, []string{"Lookup", "Lookup", "LookupPrefix", "Lookup_x"}},
+		{"Name1999", false, []string{"Name1999"}},
+		{"Name000", true, []string{"Name0000", "Name0001", "Name0002", "Name0003", "Name0004", "Name0005", "Name0006", "Name0007", "Name0008", "Name0009"}},
+		{"Missing", false, nil},
+		{"Zzz", true, nil},
+	}
+
+	for _, sorted := range []int{0, 1} {
+		src := tagsFile(names, sorted)
+		r := &countingReaderAt{r: bytes.NewReader(src)}
+		f, err := NewTagFile(r, int64(len(src)))
+		if err != nil {
+			t.Fatalf("unexpected error from NewTagFile: %s", err)
+		}
+		if len(f.MetaTags) != 2 {
+			t.Errorf("[sorted=%d] len(MetaTags) == %d, want 2", sorted, len(f.MetaTags))
+		}
+
+		for _, test := range tests {
+			r.n = 0
+			var tags []Tag
+			if test.prefix {
+				tags, err = f.LookupPrefix(test.key)
+			} else {
+				tags, err = f.Lookup(test.key)
+			}
+			if err != nil {
+				t.Errorf("[sorted=%d] unexpected error looking up %s: %s", sorted, test.key, err)
+				continue
+			}
+
+			var found []string
+			for _, tag := range tags {
+				found = append(found, tag.Name)
+			}
+			sort.Strings(found)
+			if strings.Join(found, ",") != strings.Join(test.want, ",") {
+				t.Errorf("[sorted=%d] lookup %s (prefix=%t)\n  is:%v\nwant:%v", sorted, test.key, test.prefix, found, test.want)
+			}
+			if sorted == 1 && r.n > len(src)/4 {
+				t.Errorf("[sorted=%d] lookup %s read %d of %d bytes, expected a binary search", sorted, test.key, r.n, len(src))
+			}
+		}
+	}
+}
+
+func TestTagFileLookupFoldcase(t *testing.T) {
+	src := tagsFile([]string{"alpha", "Beta", "beta", "GAMMA", "_under", "delta"}, 2)
+	f, err := NewTagFile(bytes.NewReader(src), int64(len(src)))
+	if err != nil {
+		t.Fatalf("unexpected error from NewTagFile: %s", err)
+	}
+
+	for key, want := range map[string]int{"beta": 2, "gamma": 1, "_under": 1, "DELTA": 1, "epsilon": 0} {
+		tags, err := f.Lookup(key)
+		if err != nil {
+			t.Errorf("unexpected error looking up %s: %s", key, err)
+			continue
+		}
+		if len(tags) != want {
+			t.Errorf("lookup %s: found %d tags, want %d", key, len(tags), want)
+		}
+	}
+}
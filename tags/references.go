@@ -0,0 +1,177 @@
+package tags
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// Roles of a tag, as written in the roles field.
+const (
+	RoleDef    = "def"    // definition of a symbol
+	RoleRef    = "ref"    // any other reference to a symbol
+	RoleCall   = "call"   // call of a function or method
+	RoleAssign = "assign" // assignment to a variable or field
+
+	// roles of the label references of branch statements
+	RoleGoto     = "goto"     // goto statement
+	RoleBreak    = "break"    // break statement
+	RoleContinue = "continue" // continue statement
+)
+
+// isReference reports whether tag is a reference tag rather than a
+// definition.
+func isReference(tag Tag) bool {
+	roles := tag.Fields[Roles]
+	return roles != "" && roles != RoleDef
+}
+
+// parseReferences creates a reference tag for each identifier in the function
+// signatures and bodies of files that refers to a symbol declared at the top level of the
+// package, or to a field or method of a type declared in the package, if
+// reference tags are selected in p.opts.Extra. The definition tags in p.tags
+// get the def role. Each goto, break and continue statement with a label gets a
+// label reference with the goto, break or continue role. Identifiers are
+// resolved syntactically, so a selection of a field or method is assumed to
+// refer to any field or method with that name.
+func (p *tagParser) parseReferences(files []*ast.File) {
+	if !p.opts.Extra.Includes(ReferenceTags) {
+		return
+	}
+
+	symbols := make(map[string]TagType)
+	members := make(map[string]TagType)
+	for _, tag := range p.tags {
+		switch {
+		case tag.Type == Package || tag.Type == Import || tag.Type == Directive || isLocal(tag):
+		case tag.Type == Field || tag.Type == Method:
+			if _, ok := members[tag.Name]; !ok {
+				members[tag.Name] = tag.Type
+			}
+		case tag.Fields[Scope] == "":
+			if _, ok := symbols[tag.Name]; !ok {
+				symbols[tag.Name] = tag.Type
+			}
+		}
+		tag.Fields[Roles] = RoleDef
+	}
+
+	// top-level declarations, to tell them apart from local declarations that
+	// shadow a top-level symbol
+	decls := make(map[interface{}]bool)
+	for _, f := range files {
+		for _, d := range f.Decls {
+			switch decl := d.(type) {
+			case *ast.FuncDecl:
+				decls[decl] = true
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					decls[spec] = true
+				}
+			}
+		}
+	}
+
+	for _, f := range files {
+		for _, d := range f.Decls {
+			decl, ok := d.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			if decl.Recv != nil {
+				p.parseNodeReferences(decl.Recv, symbols, members, decls)
+			}
+			p.parseNodeReferences(decl.Type, symbols, members, decls)
+			if decl.Body != nil {
+				p.parseNodeReferences(decl.Body, symbols, members, decls)
+			}
+		}
+	}
+}
+
+// parseNodeReferences creates a reference tag for each identifier in node
+// that refers to a top-level symbol in symbols, or to a field or method in
+// members, and for the label of each branch statement in node. Identifiers resolved by the parser only refer to a top-level symbol
+// if they are resolved to one of the declarations in decls, identifiers that
+// refer to a symbol declared in another file of the package are unresolved.
+func (p *tagParser) parseNodeReferences(node ast.Node, symbols, members map[string]TagType, decls map[interface{}]bool) {
+	roles := make(map[ast.Node]string)
+	keys := make(map[*ast.Ident]bool)
+	selected := make(map[*ast.Ident]bool)
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch s := n.(type) {
+		case *ast.BranchStmt:
+			if s.Label != nil {
+				p.addReference(s.Label, Label, s.Tok.String())
+			}
+			return false
+		case *ast.CallExpr:
+			roles[unparen(s.Fun)] = RoleCall
+		case *ast.AssignStmt:
+			if s.Tok != token.DEFINE {
+				for _, lhs := range s.Lhs {
+					roles[unparen(lhs)] = RoleAssign
+				}
+			}
+		case *ast.IncDecStmt:
+			roles[unparen(s.X)] = RoleAssign
+		case *ast.CompositeLit:
+			for _, elt := range s.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					if key, ok := kv.Key.(*ast.Ident); ok {
+						keys[key] = true
+					}
+				}
+			}
+		case *ast.SelectorExpr:
+			selected[s.Sel] = true
+			if x, ok := s.X.(*ast.Ident); ok && x.Obj == nil && symbols[x.Name] == "" {
+				// selection from an imported package
+				return false
+			}
+			if kind, ok := members[s.Sel.Name]; ok {
+				p.addReference(s.Sel, kind, roleOf(roles, s))
+			}
+		case *ast.Ident:
+			if selected[s] {
+				break
+			}
+			if kind, ok := members[s.Name]; ok && keys[s] {
+				// composite literal key, unless it is a map key
+				p.addReference(s, kind, RoleAssign)
+				break
+			}
+			if kind, ok := symbols[s.Name]; ok && (s.Obj == nil || decls[s.Obj.Decl]) {
+				p.addReference(s, kind, roleOf(roles, s))
+			}
+		}
+		return true
+	})
+}
+
+// roleOf returns the role of expression n as recorded in roles, or RoleRef.
+func roleOf(roles map[ast.Node]string, n ast.Node) string {
+	if role, ok := roles[n]; ok {
+		return role
+	}
+	return RoleRef
+}
+
+// unparen returns e with any enclosing parentheses removed.
+func unparen(e ast.Expr) ast.Expr {
+	for {
+		p, ok := e.(*ast.ParenExpr)
+		if !ok {
+			return e
+		}
+		e = p.X
+	}
+}
+
+// addReference creates a reference tag with the given kind and role for
+// identifier id.
+func (p *tagParser) addReference(id *ast.Ident, kind TagType, role string) {
+	tag := p.createTag(id.Name, id.Pos(), token.NoPos, kind)
+	tag.Fields[Roles] = role
+	p.tags = append(p.tags, tag)
+}
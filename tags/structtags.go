@@ -0,0 +1,58 @@
+package tags
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// structTagKeys contains the struct tag keys for which key tags are created.
+var structTagKeys = []string{"json", "yaml", "db"}
+
+// parseStructTag sets the struct tag field of tag, the tag at pos of field f in
+// the struct with name name. If key tags are selected in p.opts.Extra, a key
+// tag pointing at the field is created for each name used for the field in
+// the json, yaml or db key of the struct tag.
+func (p *tagParser) parseStructTag(tag *Tag, pos token.Pos, name string, f *ast.Field) {
+	if f.Tag == nil {
+		return
+	}
+	value, err := strconv.Unquote(f.Tag.Value)
+	if err != nil || value == "" {
+		return
+	}
+	tag.Fields[StructTag] = value
+
+	if !p.opts.Extra.Includes(KeyTags) {
+		return
+	}
+	for _, key := range structTagKeys {
+		keyName := structTagName(reflect.StructTag(value), key)
+		if keyName == "" {
+			continue
+		}
+		t := p.createTag(keyName, pos, token.NoPos, Key)
+		t.Fields[StructTag] = value
+		t.Fields[Scope] = scopeOf(Field, p.qualify(name, tag.Name))
+		p.tags = append(p.tags, t)
+	}
+}
+
+// structTagName returns the name in the value of key in struct tag st, i.e.
+// the part before the first comma. An empty string is returned if key is not
+// set, has no name or has the name "-", which means the field is ignored.
+func structTagName(st reflect.StructTag, key string) string {
+	value, ok := st.Lookup(key)
+	if !ok {
+		return ""
+	}
+	if idx := strings.IndexByte(value, ','); idx >= 0 {
+		value = value[:idx]
+	}
+	if value == "-" {
+		return ""
+	}
+	return value
+}
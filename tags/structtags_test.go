@@ -0,0 +1,27 @@
+package tags
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStructTagName(t *testing.T) {
+	var tests = []struct {
+		tag  reflect.StructTag
+		key  string
+		name string
+	}{
+		{`json:"user_id"`, "json", "user_id"},
+		{`json:"name,omitempty" yaml:"full_name"`, "json", "name"},
+		{`json:"name,omitempty" yaml:"full_name"`, "yaml", "full_name"},
+		{`json:"name"`, "db", ""},
+		{`json:"-"`, "json", ""},
+		{`json:",omitempty"`, "json", ""},
+	}
+
+	for _, test := range tests {
+		if name := structTagName(test.tag, test.key); name != test.name {
+			t.Errorf("structTagName(%q, %q) = %q, want %q", test.tag, test.key, name, test.name)
+		}
+	}
+}
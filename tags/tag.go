@@ -0,0 +1,183 @@
+// Package tags generates tags for Go source files, and writes them in the
+// tags file format used by ctags, the Emacs TAGS format or as JSON.
+//
+// A minimal program that writes the tags of a file to standard out:
+//
+//	ts, err := tags.Parse("main.go", tags.Options{})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	tags.WriteCtags(os.Stdout, nil, ts, tags.WriteOptions{Sort: true})
+package tags
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Tag represents a single tag.
+type Tag struct {
+	Name    string
+	File    string
+	Address string
+	Type    TagType
+	Fields  map[TagField]string
+	Line    int    // line of the tag in File, 0 if unknown
+	Column  int    // column of the tag in File, 0 if unknown
+	EndLine int    // line on which the declaration of the tag ends, 0 if unknown
+	Offset  int    // byte offset of the tag in File
+	Source  string // text of the source line containing the tag
+	Doc     string // text of the doc comment of the tag
+}
+
+// TagField represents a single field in a tag line.
+type TagField string
+
+// Tag fields.
+const (
+	Access              TagField = "access"
+	Signature           TagField = "signature"
+	TypeField           TagField = "type"
+	ReceiverType        TagField = "ctype"
+	Line                TagField = "line"
+	InterfaceType       TagField = "ntype"
+	Language            TagField = "language"
+	ExtraTags           TagField = "extraTag"
+	ExtraImportPathTags TagField = "extraImportPathTag"
+	LocalTags           TagField = "localTag"
+	ReferenceTags       TagField = "referenceTag"
+	KeyTags             TagField = "keyTag"
+	TypeParams          TagField = "typeparams"
+	FunctionScope       TagField = "function"
+	Build               TagField = "build"
+	FileScope           TagField = "file"
+	Scope               TagField = "scope"
+	End                 TagField = "end"
+	ColumnField         TagField = "column"
+	Implements          TagField = "implements"
+	ImplementedBy       TagField = "implementedBy"
+	Roles               TagField = "roles"
+	DocField            TagField = "doc"
+	Deprecated          TagField = "deprecated"
+	StructTag           TagField = "structTag"
+	Value               TagField = "value"
+	DirectiveField      TagField = "directive"
+	Target              TagField = "target"
+)
+
+// TagType represents the type of a tag in a tag line.
+type TagType string
+
+// Tag types.
+const (
+	Package     TagType = "p"
+	Import      TagType = "i"
+	Constant    TagType = "c"
+	Variable    TagType = "v"
+	Type        TagType = "t"
+	Interface   TagType = "n"
+	Field       TagType = "w"
+	Embedded    TagType = "e"
+	Method      TagType = "m"
+	Constructor TagType = "r"
+	Function    TagType = "f"
+	TypeParam   TagType = "Z"
+	Parameter   TagType = "z"
+	Label       TagType = "l"
+	Key         TagType = "k"
+	Directive   TagType = "D"
+)
+
+// Name returns the long name of tag type t, e.g. "function" for Function. If
+// t is unknown, its letter is returned instead.
+func (t TagType) Name() string {
+	if info, ok := lookupKind(t, ""); ok {
+		return info.name
+	}
+	return string(t)
+}
+
+// NewTag creates a new Tag.
+func NewTag(name, file string, line int, tagType TagType) Tag {
+	l := strconv.Itoa(line)
+	return Tag{
+		Name:    name,
+		File:    file,
+		Address: l,
+		Type:    tagType,
+		Fields:  map[TagField]string{Line: l},
+		Line:    line,
+	}
+}
+
+// The tags file format string representation of this tag.
+func (t Tag) String() string {
+	return t.format(string(t.Type))
+}
+
+// format returns the tags file format string representation of this tag,
+// using kind as the kind field. The kind field is omitted if kind is empty.
+func (t Tag) format(kind string) string {
+	var b bytes.Buffer
+
+	b.WriteString(t.Name)
+	b.WriteByte('\t')
+	b.WriteString(t.File)
+	b.WriteByte('\t')
+	b.WriteString(t.Address)
+	b.WriteString(";\"\t")
+	if len(kind) > 0 {
+		b.WriteString(kind)
+		b.WriteByte('\t')
+	}
+
+	fields := make([]string, 0, len(t.Fields))
+	i := 0
+	for k, v := range t.Fields {
+		if len(v) == 0 && k != FileScope {
+			continue
+		}
+		fields = append(fields, fmt.Sprintf("%s:%s", k, escapeField(v)))
+		i++
+	}
+
+	sort.Sort(sort.StringSlice(fields))
+	b.WriteString(strings.Join(fields, "\t"))
+
+	return b.String()
+}
+
+// fieldEscaper replaces the characters that cannot appear in the value of an
+// extension field by the escape sequences used by universal-ctags.
+var fieldEscaper = strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\r", "\\r", "\n", "\\n")
+
+// escapeField returns the value of an extension field with tabs, carriage
+// returns, newlines and backslashes escaped, the reverse of unescapeField.
+func escapeField(value string) string {
+	return fieldEscaper.Replace(value)
+}
+
+// sortTags sorts tags in the order they appear in a sorted tags file.
+func sortTags(tags []Tag) {
+	lines := make([]string, len(tags))
+	for i, t := range tags {
+		lines[i] = t.String()
+	}
+	sort.Sort(byLine{tags, lines})
+}
+
+// byLine implements sort.Interface to sort tags by their tags file lines.
+type byLine struct {
+	tags  []Tag
+	lines []string
+}
+
+func (b byLine) Len() int           { return len(b.tags) }
+func (b byLine) Less(i, j int) bool { return b.lines[i] < b.lines[j] }
+func (b byLine) Swap(i, j int) {
+	b.tags[i], b.tags[j] = b.tags[j], b.tags[i]
+	b.lines[i], b.lines[j] = b.lines[j], b.lines[i]
+}
@@ -0,0 +1,28 @@
+package Test
+
+import (
+	_ "embed"
+	_ "unsafe"
+)
+
+//go:generate stringer -type=Color
+
+// index is the main page.
+//
+//go:embed static/index.html
+var index string
+
+var (
+	//go:embed templates/*.tmpl
+	//go:embed static
+	files string
+)
+
+//go:linkname nanotime runtime.nanotime
+func nanotime() int64
+
+//export Add
+func Add(a, b int) int { return a + b }
+
+// not a directive:
+// go:generate ignored
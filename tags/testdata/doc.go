@@ -0,0 +1,32 @@
+// Package Test is used to test doc comments.
+package Test
+
+// Server serves requests. It is safe for concurrent use.
+type Server struct {
+	// Addr is the address to listen on.
+	Addr string
+	Port int // port number to listen on
+}
+
+// Start starts the server.
+//
+// Deprecated: Use Run instead.
+func (s *Server) Start() error { return nil }
+
+// Limits of the server.
+const (
+	// MaxConns is the maximum number of connections.
+	MaxConns = 10
+	MinConns = 1
+)
+
+// Timeout is the default timeout in seconds.
+var Timeout = 30
+
+// Handler handles a request.
+type Handler interface {
+	// Handle handles r.
+	Handle(r string)
+}
+
+func undocumented() {}
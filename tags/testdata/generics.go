@@ -0,0 +1,22 @@
+package Test
+
+type List[T any] struct {
+	items []T
+}
+
+type Set[T comparable] map[T]bool
+
+type Container[K comparable, V any] interface {
+	Get(K) V
+}
+
+type IntList List[int]
+
+func NewList[T any]() *List[T] {
+}
+
+func (l *List[T]) Push(v T) {
+}
+
+func Map[K comparable, V any](m map[K]V) Set[K] {
+}
@@ -0,0 +1,33 @@
+package shape
+
+import "io"
+
+type Shape interface {
+	Area() float64
+}
+
+type Named interface {
+	Shape
+	Name() string
+}
+
+type Square struct {
+	Size float64
+}
+
+func (s Square) Area() float64 { return s.Size * s.Size }
+
+func (s *Square) Write(p []byte) (n int, err error) { return len(p), nil }
+
+type Tile struct {
+	Square
+	Label string
+}
+
+func (t Tile) Name() string { return t.Label }
+
+type Error string
+
+func (e Error) Error() string { return string(e) }
+
+var _ io.Writer = &Square{}
@@ -0,0 +1,38 @@
+package Test
+
+import (
+	"time"
+	"unsafe"
+)
+
+type Status int
+
+const (
+	StatusOK Status = iota
+	StatusFailed
+	_
+	StatusUnknown
+)
+
+const (
+	KB = 1 << (10 * (iota + 1))
+	MB
+	GB
+)
+
+const (
+	MaxUint8  = ^uint8(0)
+	MinInt    = ^0
+	Half      = 7 / 2
+	Ratio     = 7 / 2.0
+	Name      = "gotags" + "-" + Version
+	NameLen   = len(Name)
+	Enabled   = NameLen > 3
+	Timeout   = 3 * time.Second
+	Version   = "1"
+	Undefined = Missing + 1
+	Letter    = string(rune(65))
+	Size      = unsafe.Sizeof(StatusOK)
+	Code      = Status(7)
+	Banner    = "a string constant that is longer than the seventy-two characters shown by String"
+)
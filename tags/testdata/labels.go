@@ -0,0 +1,19 @@
+package Test
+
+func Retry(n int) {
+	i := 0
+again:
+	i++
+	if i < n {
+		goto again
+	}
+rows:
+	for {
+		for {
+			if i > 0 {
+				continue rows
+			}
+			break rows
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package Test
+
+func Function(a int, b string) (n int) {
+	type point struct{ x, y int }
+	const limit = 10
+	var total int
+	count := 0
+
+	for i, v := range []int{1, 2} {
+		total += i + v
+	}
+
+	add := func(x int) int {
+		sum := total + x
+		return sum
+	}
+
+outer:
+	for {
+		count, err := add(1), error(nil)
+		_ = err
+		if count > limit {
+			break outer
+		}
+	}
+	return add(count)
+}
+
+func (s *Struct) Method() {
+	local := s
+	_ = local
+}
+
+type Struct struct{}
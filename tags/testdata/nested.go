@@ -0,0 +1,42 @@
+package Test
+
+type Config struct {
+	Name   string
+	Server struct {
+		Host string
+		Port int
+		TLS  struct {
+			Cert string
+		}
+	}
+	Logger interface {
+		Log(msg string)
+	}
+}
+
+var cfg struct {
+	Debug bool
+}
+
+var defaults = struct {
+	Retries int
+}{3}
+
+type Handler func(req struct {
+	ID int
+}) []struct {
+	Status int
+}
+
+func Handle(opts *struct {
+	Verbose bool
+}, _ map[string]struct{ Count int }) {
+}
+
+func (c Config) Validate(rules []struct{ Field string }) {}
+
+var callback = func(event struct{ Name string }) {}
+
+type Service interface {
+	Call(args struct{ Method string }) error
+}
@@ -0,0 +1,8 @@
+package server
+
+type Server struct {
+	addr string
+}
+
+func (s *Server) Start() error {
+}
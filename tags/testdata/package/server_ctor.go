@@ -0,0 +1,7 @@
+package server
+
+func NewServer(addr string) *Server {
+}
+
+func (s *Server) Stop() {
+}
@@ -0,0 +1,4 @@
+package server_test
+
+func NewServer() *Server {
+}
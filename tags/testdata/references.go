@@ -0,0 +1,24 @@
+package Test
+
+type Point struct {
+	X, Y int
+}
+
+func (p *Point) Move(dx int) {
+	p.X += dx
+}
+
+var origin = Point{X: 0, Y: 0}
+
+func NewPoint() *Point {
+	p := &Point{X: 1}
+	p.Move(2)
+	p.Y = origin.Y
+	return p
+}
+
+func shadow() {
+	origin := 1
+	origin++
+	fmt.Println(origin, NewPoint())
+}
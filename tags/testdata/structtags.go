@@ -0,0 +1,10 @@
+package Test
+
+type User struct {
+	ID       int    `json:"user_id" db:"uid"`
+	Name     string `json:"name,omitempty" yaml:"full_name"`
+	Password string `json:"-"`
+	Email    string `json:",omitempty"`
+	Address  `yaml:"address"`
+	age      int
+}
@@ -0,0 +1,11 @@
+package Test
+
+const N = 4
+
+type array1 [4]int
+type array2 [N]byte
+type array3 [N * 2]byte
+type array4 [len("abc")]string
+type array5 [2][3]*int
+type array6 [unsafe.Sizeof(x)]byte
+type array7 [0x10]bool
@@ -0,0 +1,8 @@
+package Test
+
+type chan1 chan int
+type chan2 <-chan int
+type chan3 chan<- int
+type chan4 chan<- <-chan int
+type chan5 chan (<-chan int)
+type chan6 chan struct{}
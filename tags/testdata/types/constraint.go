@@ -0,0 +1,9 @@
+package Test
+
+type constraint1 interface{ ~int }
+type constraint2 interface{ ~int | ~string }
+type constraint3 interface {
+	~int | ~int64 | float64
+	String() string
+}
+type constraint4 interface{ comparable }
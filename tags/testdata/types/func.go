@@ -0,0 +1,8 @@
+package Test
+
+type func1 func()
+type func2 func(int) string
+type func3 func(a, b int, c string) (int, error)
+type func4 func(format string, args ...interface{})
+type func5 func() (n int, err error)
+type func6 func(func(int) bool) func() string
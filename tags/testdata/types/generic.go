@@ -0,0 +1,6 @@
+package Test
+
+type generic1 List[int]
+type generic2 Map[string, *int]
+type generic3 *pkg.Set[T]
+type generic4 List[Map[K, []V]]
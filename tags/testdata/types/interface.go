@@ -0,0 +1,9 @@
+package Test
+
+type interface1 interface{}
+type interface2 interface{ M() }
+type interface3 interface {
+	io.Reader
+	Write(p []byte) (n int, err error)
+	Close() error
+}
@@ -0,0 +1,5 @@
+package Test
+
+type map1 map[string]bool
+type map2 map[*Key][]Value
+type map3 map[[2]int]map[string]int
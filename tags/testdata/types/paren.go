@@ -0,0 +1,5 @@
+package Test
+
+type paren1 (int)
+type paren2 *(int)
+type paren3 [](func())
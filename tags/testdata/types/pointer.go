@@ -0,0 +1,5 @@
+package Test
+
+type pointer1 *string
+type pointer2 **int
+type pointer3 *[]*bool
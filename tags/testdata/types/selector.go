@@ -0,0 +1,5 @@
+package Test
+
+type selector1 io.Reader
+type selector2 *bytes.Buffer
+type selector3 []ast.Node
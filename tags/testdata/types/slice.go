@@ -0,0 +1,5 @@
+package Test
+
+type slice1 []int
+type slice2 [][]string
+type slice3 []map[string]int
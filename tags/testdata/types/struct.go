@@ -0,0 +1,10 @@
+package Test
+
+type struct1 map[string]struct{}
+type struct2 []struct {
+	X, Y int
+	name string `json:"name"`
+	io.Reader
+	*Embedded
+}
+type struct3 chan struct{ f func() }
@@ -0,0 +1,190 @@
+package tags
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"strings"
+)
+
+// getTypes returns a comma separated list of types in fields. If includeNames is
+// true each type is preceded by a comma separated list of parameter names.
+func getTypes(fields *ast.FieldList, includeNames bool) string {
+	if fields == nil {
+		return ""
+	}
+
+	types := make([]string, len(fields.List))
+	for i, param := range fields.List {
+		if len(param.Names) > 0 {
+			// there are named parameters, there may be multiple names for a single type
+			t := getType(param.Type, true)
+
+			if includeNames {
+				// join all the names, followed by their type
+				t = fmt.Sprintf("%s %s", getNames(param.Names), t)
+			} else {
+				if len(param.Names) > 1 {
+					// repeat t len(param.Names) times
+					t = strings.Repeat(fmt.Sprintf("%s, ", t), len(param.Names))
+
+					// remove trailing comma and space
+					t = t[:len(t)-2]
+				}
+			}
+
+			types[i] = t
+		} else {
+			// no named parameters
+			types[i] = getType(param.Type, true)
+		}
+	}
+
+	return strings.Join(types, ", ")
+}
+
+// getType returns a string representation of the type of node. If star is true and the
+// type is a pointer, a * will be prepended to the string.
+func getType(node ast.Node, star bool) (paramType string) {
+	switch t := node.(type) {
+	case *ast.Ident:
+		paramType = t.Name
+	case *ast.BasicLit:
+		paramType = t.Value
+	case *ast.StarExpr:
+		if star {
+			paramType = "*" + getType(t.X, star)
+		} else {
+			paramType = getType(t.X, star)
+		}
+	case *ast.ParenExpr:
+		paramType = fmt.Sprintf("(%s)", getType(t.X, star))
+	case *ast.SelectorExpr:
+		paramType = getType(t.X, star) + "." + getType(t.Sel, star)
+	case *ast.ArrayType:
+		if t.Len == nil {
+			paramType = "[]" + getType(t.Elt, star)
+		} else if _, ok := t.Len.(*ast.Ellipsis); ok {
+			paramType = "[...]" + getType(t.Elt, star)
+		} else {
+			paramType = fmt.Sprintf("[%s]%s", getType(t.Len, true), getType(t.Elt, star))
+		}
+	case *ast.FuncType:
+		fparams := getTypes(t.Params, true)
+		fresult := getResults(t.Results)
+
+		if len(fresult) > 0 {
+			paramType = fmt.Sprintf("func(%s) %s", fparams, fresult)
+		} else {
+			paramType = fmt.Sprintf("func(%s)", fparams)
+		}
+	case *ast.MapType:
+		paramType = fmt.Sprintf("map[%s]%s", getType(t.Key, true), getType(t.Value, true))
+	case *ast.ChanType:
+		switch t.Dir {
+		case ast.SEND:
+			paramType = fmt.Sprintf("chan<- %s", getType(t.Value, true))
+		case ast.RECV:
+			paramType = fmt.Sprintf("<-chan %s", getType(t.Value, true))
+		default:
+			paramType = fmt.Sprintf("chan %s", getType(t.Value, true))
+		}
+	case *ast.StructType:
+		paramType = getFieldList("struct", t.Fields)
+	case *ast.InterfaceType:
+		paramType = getFieldList("interface", t.Methods)
+	case *ast.Ellipsis:
+		paramType = fmt.Sprintf("...%s", getType(t.Elt, true))
+	case *ast.IndexExpr:
+		paramType = fmt.Sprintf("%s[%s]", getType(t.X, star), getType(t.Index, true))
+	case *ast.IndexListExpr:
+		indices := make([]string, len(t.Indices))
+		for i, index := range t.Indices {
+			indices[i] = getType(index, true)
+		}
+		paramType = fmt.Sprintf("%s[%s]", getType(t.X, star), strings.Join(indices, ", "))
+	case *ast.UnaryExpr:
+		paramType = t.Op.String() + getType(t.X, true)
+	case *ast.BinaryExpr:
+		paramType = fmt.Sprintf("%s %s %s", getType(t.X, true), t.Op, getType(t.Y, true))
+	case ast.Expr:
+		// any other expression can only appear as the length of an array
+		paramType = getExpr(t)
+	}
+	return
+}
+
+// getResults returns the string representation of the results of a function
+// type. Parentheses are added unless there is only a single unnamed result.
+func getResults(results *ast.FieldList) string {
+	if results == nil || len(results.List) == 0 {
+		return ""
+	}
+	if len(results.List) == 1 && len(results.List[0].Names) == 0 {
+		return getType(results.List[0].Type, true)
+	}
+	return fmt.Sprintf("(%s)", getTypes(results, true))
+}
+
+// getFieldList returns the single line string representation of a struct or
+// interface type, e.g. struct{ A, B int; c string } or interface{ M() }.
+func getFieldList(keyword string, fields *ast.FieldList) string {
+	if fields == nil || len(fields.List) == 0 {
+		return keyword + "{}"
+	}
+
+	elems := make([]string, len(fields.List))
+	for i, f := range fields.List {
+		var elem string
+		if ft, ok := f.Type.(*ast.FuncType); ok && keyword == "interface" && len(f.Names) > 0 {
+			// interface method
+			elem = f.Names[0].Name + strings.TrimPrefix(getType(ft, true), "func")
+		} else if len(f.Names) > 0 {
+			elem = fmt.Sprintf("%s %s", getNames(f.Names), getType(f.Type, true))
+		} else {
+			// embedded field, embedded interface or type constraint
+			elem = getType(f.Type, true)
+		}
+		if f.Tag != nil {
+			elem = fmt.Sprintf("%s %s", elem, f.Tag.Value)
+		}
+		elems[i] = elem
+	}
+
+	return fmt.Sprintf("%s{ %s }", keyword, strings.Join(elems, "; "))
+}
+
+// getNames returns a comma separated list of names.
+func getNames(idents []*ast.Ident) string {
+	names := make([]string, len(idents))
+	for i, n := range idents {
+		names[i] = n.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// getExpr returns the string representation of an arbitrary expression as
+// formatted by go/printer, normalized to fit on a single line.
+func getExpr(expr ast.Expr) string {
+	var b bytes.Buffer
+	if err := printer.Fprint(&b, token.NewFileSet(), expr); err != nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// getBaseType returns the name of the type of node, without any pointers or
+// type arguments. For example, the base type of *List[T] is List.
+func getBaseType(node ast.Node) string {
+	switch t := node.(type) {
+	case *ast.StarExpr:
+		return getBaseType(t.X)
+	case *ast.IndexExpr:
+		return getBaseType(t.X)
+	case *ast.IndexListExpr:
+		return getBaseType(t.X)
+	}
+	return getType(node, false)
+}
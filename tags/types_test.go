@@ -0,0 +1,80 @@
+package tags
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata/types")
+
+// TestGetTypeGolden prints the type of every type declaration in the files in
+// testdata/types and compares the output with the matching .golden file.
+func TestGetTypeGolden(t *testing.T) {
+	files, err := filepath.Glob("testdata/types/*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, filename := range files {
+		f, err := parser.ParseFile(token.NewFileSet(), filename, nil, 0)
+		if err != nil {
+			t.Errorf("[%s] parse error: %s", filename, err)
+			continue
+		}
+
+		var b bytes.Buffer
+		for _, d := range f.Decls {
+			decl, ok := d.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, s := range decl.Specs {
+				if ts, ok := s.(*ast.TypeSpec); ok {
+					fmt.Fprintf(&b, "%s\t%s\n", ts.Name.Name, getType(ts.Type, true))
+				}
+			}
+		}
+
+		golden := strings.TrimSuffix(filename, ".go") + ".golden"
+		if *updateGolden {
+			if err := os.WriteFile(golden, b.Bytes(), 0644); err != nil {
+				t.Errorf("[%s] could not update golden file: %s", filename, err)
+			}
+			continue
+		}
+
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Errorf("[%s] could not read golden file: %s", filename, err)
+			continue
+		}
+		if b.String() != string(want) {
+			t.Errorf("[%s] getType\n  is:\n%s\nwant:\n%s", filename, b.String(), want)
+		}
+	}
+}
+
+func TestGetTypeStar(t *testing.T) {
+	expr, err := parser.ParseExpr("**List[*int]")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := getType(expr, true); s != "**List[*int]" {
+		t.Errorf("getType(star=true) = %q, want %q", s, "**List[*int]")
+	}
+	if s := getType(expr, false); s != "List[*int]" {
+		t.Errorf("getType(star=false) = %q, want %q", s, "List[*int]")
+	}
+	if s := getBaseType(expr); s != "List" {
+		t.Errorf("getBaseType = %q, want %q", s, "List")
+	}
+}
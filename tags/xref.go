@@ -0,0 +1,33 @@
+package tags
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteXref writes tags to w as a cross reference listing, similar to the
+// output of ctags -x. Each line contains the name, kind, line number and file
+// of a tag, followed by the source line containing it. The meta tags have no
+// representation in this format and are ignored.
+func WriteXref(w io.Writer, metaTags []MetaTag, tags []Tag, opts WriteOptions) error {
+	if opts.Sort {
+		sortTags(tags)
+	}
+
+	for _, t := range tags {
+		source := strings.TrimSpace(t.Source)
+		if _, err := fmt.Fprintf(w, "%-16s %-10s %4s %-16s %s\n", t.Name, t.Type.Name(), tagLine(t), t.File, source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tagLine returns the line number of tag t.
+func tagLine(t Tag) string {
+	if l, ok := t.Fields[Line]; ok {
+		return l
+	}
+	return addressLine(t.Address)
+}
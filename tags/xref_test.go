@@ -0,0 +1,27 @@
+package tags
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteXref(t *testing.T) {
+	tags, err := Parse("testdata/const.go", Options{})
+	if err != nil {
+		t.Fatalf("unexpected error from Parse: %s", err)
+	}
+
+	var b bytes.Buffer
+	if err := WriteXref(&b, nil, tags[:4], WriteOptions{Sort: true}); err != nil {
+		t.Fatalf("unexpected error from WriteXref: %s", err)
+	}
+
+	expected := "A                constant      7 testdata/const.go A    = true\n" +
+		"Constant         constant      3 testdata/const.go const Constant string = \"const\"\n" +
+		"OtherConst       constant      4 testdata/const.go const OtherConst = \"const\"\n" +
+		"Test             package       1 testdata/const.go package Test\n"
+
+	if b.String() != expected {
+		t.Errorf("WriteXref()\n  is:%q\nwant:%q", b.String(), expected)
+	}
+}
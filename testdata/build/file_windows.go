@@ -0,0 +1,4 @@
+package build
+
+func Windows() {
+}
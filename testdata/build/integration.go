@@ -0,0 +1,9 @@
+// Copyright notice.
+
+//go:build integration && !race
+// +build integration,!race
+
+package build
+
+func Integration() {
+}
@@ -0,0 +1,7 @@
+// +build linux darwin
+// +build cgo
+
+package build
+
+func Cgo() {
+}
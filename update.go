@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jstemmer/gotags/tags"
+)
+
+// readTagLines returns the lines of the tags file filename. If the file does
+// not exist yet, no lines are returned.
+func readTagLines(filename string) ([]string, error) {
+	f, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// keepTagLines returns the lines of an existing tags file that remain valid
+// when the tags of the files in replaced are regenerated. Pseudo tags, tags of
+// files in replaced and tags of files that no longer exist are removed. File
+// names that are not absolute are resolved relative to basedir.
+func keepTagLines(lines []string, replaced []string, basedir string) []string {
+	skip := make(map[string]bool)
+	for _, name := range replaced {
+		skip[filepath.Clean(name)] = true
+	}
+
+	exists := make(map[string]bool)
+	var kept []string
+	for _, line := range lines {
+		if strings.HasPrefix(line, "!_") {
+			continue
+		}
+
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) < 3 {
+			continue
+		}
+
+		name := filepath.Clean(fields[1])
+		if skip[name] {
+			continue
+		}
+
+		ok, seen := exists[name]
+		if !seen {
+			path := name
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(basedir, path)
+			}
+			_, err := os.Stat(path)
+			ok = !os.IsNotExist(err)
+			exists[name] = ok
+		}
+		if ok {
+			kept = append(kept, line)
+		}
+	}
+	return kept
+}
+
+// updateWriter returns a function that writes tags merged with the tags in the
+// existing tags file outputFile, without the tags of files.
+func updateWriter(files []string, basedir string) (func(io.Writer, []tags.MetaTag, []tags.Tag, tags.WriteOptions) error, error) {
+	lines, err := readTagLines(outputFile)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := parseOptions(basedir)
+	replaced := make([]string, len(files))
+	for i, file := range files {
+		replaced[i] = opts.FileName(file)
+	}
+	kept := keepTagLines(lines, replaced, basedir)
+
+	return func(w io.Writer, metaTags []tags.MetaTag, parsed []tags.Tag, opts tags.WriteOptions) error {
+		return tags.MergeCtags(w, metaTags, parsed, kept, opts)
+	}, nil
+}
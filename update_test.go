@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeepTagLines(t *testing.T) {
+	lines := []string{
+		"!_TAG_FILE_FORMAT\t2",
+		"!_TAG_FILE_SORTED\t1\t/0=unsorted, 1=sorted/",
+		"Constant\ttags/testdata/const.go\t3;\"\tc\taccess:public\tline:3\ttype:string",
+		"Function1\ttags/testdata/func.go\t3;\"\tf\taccess:public\tline:3\tsignature:()\ttype:string",
+		"Deleted\ttags/testdata/deleted.go\t3;\"\tf\taccess:public\tline:3\tsignature:()",
+		"Struct\t./tags/testdata/struct.go\t3;\"\tt\taccess:public\tline:3\ttype:struct",
+		"invalid line",
+	}
+	expected := []string{
+		"Constant\ttags/testdata/const.go\t3;\"\tc\taccess:public\tline:3\ttype:string",
+	}
+
+	kept := keepTagLines(lines, []string{"tags/testdata/func.go", "tags/testdata/struct.go"}, "")
+	if len(kept) != len(expected) {
+		t.Fatalf("len(kept) == %d, want %d", len(kept), len(expected))
+	}
+	for i := range expected {
+		if kept[i] != expected[i] {
+			t.Errorf("line(%d)\n  is:%s\nwant:%s", i, kept[i], expected[i])
+		}
+	}
+}
+
+func TestUpdateTagsFile(t *testing.T) {
+	dir := t.TempDir()
+	a, b, c := filepath.Join(dir, "a.go"), filepath.Join(dir, "b.go"), filepath.Join(dir, "c.go")
+	writeFile := func(name, src string) {
+		t.Helper()
+		if err := os.WriteFile(name, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(a, "package p\n\nfunc A() {}\n")
+	writeFile(b, "package p\n\nfunc B() {}\n")
+	writeFile(c, "package p\n\nfunc C() {}\n")
+
+	defer func(o string) { outputFile = o }(outputFile)
+	outputFile = filepath.Join(dir, "tags")
+
+	if err := writeTags(writers["ctags"], parseFiles([]string{a, b, c}, "")); err != nil {
+		t.Fatalf("unexpected error from writeTags: %s", err)
+	}
+
+	// edit a.go and delete b.go, then update the tags of a.go as -a does
+	writeFile(a, "package p\n\nfunc A2() {}\n")
+	if err := os.Remove(b); err != nil {
+		t.Fatal(err)
+	}
+	write, err := updateWriter([]string{a}, "")
+	if err != nil {
+		t.Fatalf("unexpected error from updateWriter: %s", err)
+	}
+	if err := writeTags(write, parseFiles([]string{a}, "")); err != nil {
+		t.Fatalf("unexpected error from writeTags: %s", err)
+	}
+
+	lines, err := readTagLines(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var header []string
+	var tagNames []string
+	for _, line := range lines {
+		if strings.HasPrefix(line, "!_") {
+			header = append(header, line[:strings.IndexByte(line, '\t')])
+			continue
+		}
+		fields := strings.SplitN(line, "\t", 3)
+		tagNames = append(tagNames, fields[0]+" "+filepath.Base(fields[1]))
+	}
+
+	expected := []string{"A2 a.go", "C c.go", "p a.go", "p c.go"}
+	if fmt.Sprint(tagNames) != fmt.Sprint(expected) {
+		t.Errorf("tags after update = %v, want %v", tagNames, expected)
+	}
+
+	seen := make(map[string]int)
+	for _, name := range header {
+		seen[name]++
+	}
+	for _, name := range []string{"!_TAG_FILE_FORMAT", "!_TAG_FILE_SORTED", "!_TAG_PROGRAM_NAME", "!_TAG_PROGRAM_VERSION"} {
+		if seen[name] != 1 {
+			t.Errorf("header contains %s %d times, want once", name, seen[name])
+		}
+	}
+	if len(lines) == 0 || !strings.HasPrefix(lines[0], "!_") {
+		t.Error("expected the tags file to start with the header")
+	}
+}
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jstemmer/gotags/tags"
+)
+
+// fileState is the state of a watched file, used to detect changes.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// scanFiles returns the state of each Go file in files. Files that do not
+// exist are not included.
+func scanFiles(files []string) map[string]fileState {
+	states := make(map[string]fileState, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil || info.IsDir() || filepath.Ext(file) != ".go" {
+			continue
+		}
+		states[file] = fileState{info.ModTime(), info.Size()}
+	}
+	return states
+}
+
+// changedFiles returns the sorted names of the files that were created,
+// modified or removed between the states prev and cur.
+func changedFiles(prev, cur map[string]fileState) []string {
+	var changed []string
+	for file, state := range cur {
+		if p, ok := prev[file]; !ok || !p.modTime.Equal(state.modTime) || p.size != state.size {
+			changed = append(changed, file)
+		}
+	}
+	for file := range prev {
+		if _, ok := cur[file]; !ok {
+			changed = append(changed, file)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// watcher keeps the tags of the files in names up to date in outputFile.
+type watcher struct {
+	names   []string
+	basedir string
+	state   map[string]fileState // state of the watched files
+
+	// The files in names are cached, since resolving package patterns runs go
+	// list. They are only resolved again when one of dirs changes.
+	files    []string
+	dirNames []string             // directories in which the files of names are found
+	dirs     map[string]time.Time // modification time of each directory in dirNames
+}
+
+// newWatcher returns a watcher for the files in names, of which the files
+// initially found are passed in files.
+func newWatcher(names, files []string, basedir string) *watcher {
+	w := &watcher{names: names, basedir: basedir, files: files}
+	w.dirNames = watchedDirs(names, files)
+	w.dirs = scanDirs(w.dirNames)
+	w.state = scanFiles(files)
+	return w
+}
+
+// watch polls the files in names for changes every watchPeriod and updates
+// the tags of changed files in outputFile. The files initially found in names
+// are passed in files. watch never returns.
+func watch(names, files []string, basedir string) {
+	w := newWatcher(names, files, basedir)
+	for {
+		time.Sleep(watchPeriod)
+		w.poll()
+	}
+}
+
+// poll checks the watched files for changes once, and reports whether any
+// were found. A burst of changes, for example from a git checkout, is handled
+// in a single update once no more changes are detected. Renamed files are
+// handled as a removal followed by a creation.
+func (w *watcher) poll() bool {
+	cur := scanFiles(w.listFiles())
+	changed := changedFiles(w.state, cur)
+	if len(changed) == 0 {
+		return false
+	}
+
+	// wait until the files stop changing
+	for {
+		time.Sleep(watchPeriod)
+		next := scanFiles(w.listFiles())
+		more := changedFiles(cur, next)
+		if len(more) == 0 {
+			break
+		}
+		changed = mergeNames(changed, more)
+		cur = next
+	}
+	w.state = cur
+
+	if err := updateTagsFile(changed, w.state, w.basedir); err != nil && !silent {
+		fmt.Fprintf(os.Stderr, "could not update tags file: %s\n", err)
+	}
+	return true
+}
+
+// listFiles returns the files in w.names that should be watched. The names are
+// only resolved again if a file was added to or removed from one of the
+// watched directories since the previous call.
+func (w *watcher) listFiles() []string {
+	if equalTimes(scanDirs(w.dirNames), w.dirs) {
+		return w.files
+	}
+
+	files, err := getFileNames(w.names)
+	if err != nil {
+		if !silent {
+			fmt.Fprintf(os.Stderr, "cannot get specified files: %s\n", err)
+		}
+		return w.files
+	}
+	w.files = files
+	w.dirNames = watchedDirs(w.names, files)
+	w.dirs = scanDirs(w.dirNames)
+	return w.files
+}
+
+// watchedDirs returns the directories that contain files, the directories in
+// names and, for directories in names with recurse set and for local package
+// patterns such as ./..., all the directories below them. Adding or removing
+// a file that belongs to names changes one of these directories.
+func watchedDirs(names, files []string) []string {
+	dirs := make(map[string]bool)
+	for _, file := range files {
+		dirs[filepath.Dir(file)] = true
+	}
+
+	for _, name := range names {
+		root, tree := name, recurse
+		if strings.HasSuffix(name, "/...") {
+			root, tree = strings.TrimSuffix(name, "/..."), true
+		}
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			continue
+		}
+		dirs[filepath.Clean(root)] = true
+		if !tree {
+			continue
+		}
+		filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil || !info.IsDir() {
+				return nil
+			}
+			if base := info.Name(); path != root && (strings.HasPrefix(base, ".") || strings.HasPrefix(base, "_")) {
+				return filepath.SkipDir
+			}
+			dirs[path] = true
+			return nil
+		})
+	}
+	list := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		list = append(list, dir)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// scanDirs returns the modification time of each directory in dirs.
+// Directories that do not exist are not included.
+func scanDirs(dirs []string) map[string]time.Time {
+	times := make(map[string]time.Time, len(dirs))
+	for _, dir := range dirs {
+		if info, err := os.Stat(dir); err == nil {
+			times[dir] = info.ModTime()
+		}
+	}
+	return times
+}
+
+// equalTimes reports whether a and b contain the same names and times.
+func equalTimes(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, t := range a {
+		if u, ok := b[name]; !ok || !t.Equal(u) {
+			return false
+		}
+	}
+	return true
+}
+
+// updateTagsFile replaces the tags of the changed files in outputFile. Only
+// the changed files that still exist in state are parsed again. With -pkg or
+// -types, the tags of a file depend on the other files of its package, such
+// as constructors and implemented interfaces, so all the files of the packages
+// of the changed files are parsed again.
+func updateTagsFile(changed []string, state map[string]fileState, basedir string) error {
+	var files []string
+	if packageMode || typeCheck {
+		files = packageFiles(changed, state)
+	} else {
+		for _, file := range changed {
+			if _, ok := state[file]; ok {
+				files = append(files, file)
+			}
+		}
+	}
+
+	write, err := updateWriter(mergeNames(changed, files), basedir)
+	if err != nil {
+		return err
+	}
+
+	return writeTags(write, parseFiles(files, basedir))
+}
+
+// packageFiles returns the sorted files in state that belong to the same
+// package as one of the changed files. The package of a removed file is not
+// known, so all the packages in its directory are included.
+func packageFiles(changed []string, state map[string]fileState) []string {
+	isChanged := make(map[string]bool)
+	dirs := make(map[string]bool)
+	removedDirs := make(map[string]bool)
+	for _, file := range changed {
+		isChanged[file] = true
+		dirs[filepath.Dir(file)] = true
+		if _, ok := state[file]; !ok {
+			removedDirs[filepath.Dir(file)] = true
+		}
+	}
+
+	var candidates []string
+	for file := range state {
+		if dirs[filepath.Dir(file)] {
+			candidates = append(candidates, file)
+		}
+	}
+	sort.Strings(candidates)
+
+	var files []string
+	for _, group := range tags.GroupPackages(candidates) {
+		include := removedDirs[filepath.Dir(group[0])]
+		for _, file := range group {
+			include = include || isChanged[file]
+		}
+		if include {
+			files = append(files, group...)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// writeFileAtomic writes to a temporary file using write and then renames it
+// to filename, so readers of filename never see a partially written file. If
+// filename is a symbolic link, the file it points to is replaced. Files that
+// are not regular files, such as /dev/null, are written directly.
+func writeFileAtomic(filename string, write func(io.Writer) error) error {
+	if target, err := filepath.EvalSymlinks(filename); err == nil {
+		filename = target
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(filename); err == nil {
+		if !info.Mode().IsRegular() {
+			f, err := os.OpenFile(filename, os.O_WRONLY|os.O_TRUNC, 0)
+			if err != nil {
+				return err
+			}
+			if err := write(f); err != nil {
+				f.Close()
+				return err
+			}
+			return f.Close()
+		}
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+// mergeNames returns the sorted union of the sorted name lists a and b.
+func mergeNames(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var names []string
+	for _, list := range [][]string{a, b} {
+		for _, name := range list {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
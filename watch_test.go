@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChangedFiles(t *testing.T) {
+	now := time.Now()
+	prev := map[string]fileState{
+		"a.go": {now, 10},
+		"b.go": {now, 10},
+		"c.go": {now, 10},
+		"d.go": {now, 10},
+	}
+	cur := map[string]fileState{
+		"a.go": {now, 10},
+		"b.go": {now.Add(time.Second), 10},
+		"c.go": {now, 12},
+		"e.go": {now, 10},
+	}
+
+	changed := changedFiles(prev, cur)
+	expected := []string{"b.go", "c.go", "d.go", "e.go"}
+	if fmt.Sprint(changed) != fmt.Sprint(expected) {
+		t.Errorf("changedFiles() = %v, want %v", changed, expected)
+	}
+}
+
+func TestScanFiles(t *testing.T) {
+	states := scanFiles([]string{"tags/testdata/const.go", "tags/testdata/missing.go", "testdata", "README.md"})
+	if len(states) != 1 {
+		t.Fatalf("len(states) == %d, want 1", len(states))
+	}
+	if _, ok := states["tags/testdata/const.go"]; !ok {
+		t.Error("expected states to include tags/testdata/const.go")
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "tags")
+
+	if err := os.WriteFile(filename, []byte("old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := writeFileAtomic(filename, func(w io.Writer) error {
+		_, err := io.WriteString(w, "new\n")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error from writeFileAtomic: %s", err)
+	}
+
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "new\n" {
+		t.Errorf("file content = %q, want %q", b, "new\n")
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("file mode = %v, want %v", info.Mode().Perm(), os.FileMode(0600))
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("expected temporary file to be removed, found %d files", len(files))
+	}
+}
+
+func TestMergeNames(t *testing.T) {
+	names := mergeNames([]string{"a.go", "c.go"}, []string{"b.go", "c.go"})
+	expected := []string{"a.go", "b.go", "c.go"}
+	if fmt.Sprint(names) != fmt.Sprint(expected) {
+		t.Errorf("mergeNames() = %v, want %v", names, expected)
+	}
+}
+
+func TestWatcherPoll(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, src string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("a.go", "package p\n\nfunc A() {}\n")
+	writeFile("b.go", "package p\n\nfunc B() {}\n")
+
+	defer func(o string, r bool, p time.Duration) { outputFile, recurse, watchPeriod = o, r, p }(outputFile, recurse, watchPeriod)
+	outputFile = filepath.Join(dir, "tags")
+	recurse = true
+	watchPeriod = time.Millisecond
+
+	names := []string{dir}
+	files, err := getFileNames(names)
+	if err != nil {
+		t.Fatalf("unexpected error from getFileNames: %s", err)
+	}
+	if err := writeTags(writers["ctags"], parseFiles(files, "")); err != nil {
+		t.Fatalf("unexpected error from writeTags: %s", err)
+	}
+
+	w := newWatcher(names, files, "")
+	if w.poll() {
+		t.Fatal("poll() reported changes for unchanged files")
+	}
+
+	// modify a.go, remove b.go and add c.go
+	writeFile("a.go", "package p\n\nfunc A2() {}\n")
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(dir, "a.go"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "b.go")); err != nil {
+		t.Fatal(err)
+	}
+	writeFile("c.go", "package p\n\nfunc C() {}\n")
+	if err := os.Chtimes(dir, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	if !w.poll() {
+		t.Fatal("poll() did not report changes")
+	}
+
+	b, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tagNames []string
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		if !strings.HasPrefix(line, "!_") {
+			tagNames = append(tagNames, line[:strings.IndexByte(line, '\t')])
+		}
+	}
+	expected := []string{"A2", "C", "p", "p"}
+	if fmt.Sprint(tagNames) != fmt.Sprint(expected) {
+		t.Errorf("tags after update = %v, want %v", tagNames, expected)
+	}
+
+	// the names are not resolved again while the directory is unchanged, the
+	// first call notices the tags file written to it
+	w.listFiles()
+	w.names = nil
+	if files := w.listFiles(); len(files) != 2 {
+		t.Errorf("listFiles() = %v, want the cached a.go and c.go", files)
+	}
+}
+
+func TestWatcherPollPackage(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, src string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("a.go", "package p\n\ntype T struct{}\n")
+	writeFile("b.go", "package p\n\nfunc NewT() *T { return nil }\n")
+
+	defer func(o string, r, p bool, d time.Duration) {
+		outputFile, recurse, packageMode, watchPeriod = o, r, p, d
+	}(outputFile, recurse, packageMode, watchPeriod)
+	outputFile = filepath.Join(dir, "tags")
+	recurse = true
+	packageMode = true
+	watchPeriod = time.Millisecond
+
+	names := []string{dir}
+	files, err := getFileNames(names)
+	if err != nil {
+		t.Fatalf("unexpected error from getFileNames: %s", err)
+	}
+	if err := writeTags(writers["ctags"], parseFiles(files, "")); err != nil {
+		t.Fatalf("unexpected error from writeTags: %s", err)
+	}
+
+	// the constructor in b.go refers to the type in the unchanged a.go
+	w := newWatcher(names, files, "")
+	writeFile("b.go", "package p\n\nfunc NewT() *T { return &T{} }\n")
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(dir, "b.go"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if !w.poll() {
+		t.Fatal("poll() did not report changes")
+	}
+
+	lines, err := readTagLines(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, line := range lines {
+		if strings.HasPrefix(line, "NewT\t") {
+			found = true
+			if !strings.Contains(line, "\tctype:T") {
+				t.Errorf("constructor lost its type after update: %s", line)
+			}
+		}
+	}
+	if !found {
+		t.Error("NewT not found after update")
+	}
+}